@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -44,14 +45,116 @@ type CertificateRequestPolicyList struct {
 }
 
 type CertificateRequestPolicySpec struct {
+	// Subjects, if set, restricts the requesters this policy is bound to, in
+	// addition to or instead of the RBAC "use" permission on this policy.
+	// Subjects without any matcher match no requester.
+	// +optional
+	Subjects *PolicySubjects `json:"subjects,omitempty"`
+
+	// Tier is the tier of this policy when bound policies are combined in the
+	// Layered mode. Tiers are evaluated in ascending order; every bound policy
+	// of each tier must approve the request, and at least one bound policy of
+	// the final tier. Policies of the same tier are evaluated together, so if
+	// every bound policy has the same tier, any one of them may approve the
+	// request. Defaults to 0.
+	// +optional
+	Tier *int32 `json:"tier,omitempty"`
+
+	// PolicyConstraints are evaluated against every request.
+	PolicyConstraints `json:",inline"`
+
+	// Rules, if set, are conditional constraints. A request must satisfy at
+	// least one rule which matches it, in addition to the constraints of the spec.
+	// Requests which match no rule are denied.
+	// +optional
+	Rules []PolicyRule `json:"rules,omitempty"`
+
+	// Denial, if set, customises the reason given when this policy denies a
+	// request, in the Denied condition and events of the request.
+	// +optional
+	Denial *PolicyDenial `json:"denial,omitempty"`
+
+	// Limits are enforced before any other field of the request is evaluated.
+	// +optional
+	Limits *PolicyLimits `json:"limits,omitempty"`
+
+	// +optional
+	ExternalPolicyServers []string `json:"externalPolicyServers,omitempty"`
+}
+
+// PolicyDenial customises the reason given when a policy denies a request.
+type PolicyDenial struct {
+	// Message replaces the reason of every violated constraint which does not
+	// have its own message. The field path and value which violated the
+	// constraint are kept.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// RemediationURL is a link to documentation on how to satisfy the policy,
+	// appended to the reason of every violated constraint which does not have
+	// its own remediation URL.
+	// +optional
+	RemediationURL string `json:"remediationURL,omitempty"`
+
+	// Constraints customise the reason given for individual constraints.
+	// +optional
+	Constraints []PolicyConstraintDenial `json:"constraints,omitempty"`
+}
+
+// PolicyConstraintDenial customises the reason given when a constraint is
+// violated.
+type PolicyConstraintDenial struct {
+	// Path is the field path of the constraint, for example
+	// "spec.allowedDNSNames" or "spec.rules[internal].allowedSubject". Applies
+	// to violations of the constraint and any of its fields. The constraint
+	// with the longest matching path is used. Requests are denied if the path
+	// is not the field path of the policy spec or one of its fields.
+	Path string `json:"path"`
+
+	// Message replaces the reason the constraint was violated. The field path
+	// and value which violated the constraint are kept.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// RemediationURL is a link to documentation on how to satisfy the
+	// constraint.
+	// +optional
+	RemediationURL string `json:"remediationURL,omitempty"`
+}
+
+// PolicyConstraints are the constraints a request must satisfy.
+type PolicyConstraints struct {
 	// +optional
 	AllowedSubject *PolicyX509Subject `json:"allowedSubject,omitempty"`
 
 	// +optional
 	AllowedCommonName *string `json:"allowedCommonName,omitempty"`
 
+	// DeniedCommonNames are patterns of common names which are denied, even if
+	// allowed by AllowedCommonName. Common names are matched as DNS names
+	// would be by DeniedDNSNames.
+	// +optional
+	DeniedCommonNames *[]string `json:"deniedCommonNames,omitempty"`
+
+	// CommonNameMustBeSAN requires a non-empty common name to also be requested
+	// as a DNS name, IP address or email address.
+	// +optional
+	CommonNameMustBeSAN *bool `json:"commonNameMustBeSAN,omitempty"`
+
+	// CommonNameFormat restricts the syntax of a non-empty common name.
+	// +optional
+	CommonNameFormat *CommonNameFormat `json:"commonNameFormat,omitempty"`
+
+	// RequireExplicitDuration requires the request to specify a duration,
+	// rather than be issued with the default duration of its issuer.
+	// +optional
+	RequireExplicitDuration *bool `json:"requireExplicitDuration,omitempty"`
+
 	// Values are inclusive (i.e. a min value with 50s will accept a duration
-	// with 50s). MinDuration and MaxDuration may be the same.
+	// with 50s). MinDuration and MaxDuration may be the same. Requests without
+	// a duration are evaluated against the duration of the
+	// "policy.cert-manager.io/default-duration" annotation of their
+	// ClusterIssuer, else the default duration of the approver.
 	// +optional
 	MinDuration *metav1.Duration `json:"minDuration,omitempty"`
 	// +optional
@@ -60,17 +163,45 @@ type CertificateRequestPolicySpec struct {
 	// +optional
 	AllowedDNSNames *[]string `json:"allowedDNSNames,omitempty"`
 
+	// DeniedDNSNames are patterns of DNS names which are denied, even if
+	// allowed by AllowedDNSNames. For example, "login.example.com" and
+	// "*.admin.example.com" carve exceptions out of "*.example.com". DNS names
+	// are compared case insensitively, and a wildcard DNS name is denied if any
+	// name it covers is denied.
+	// +optional
+	DeniedDNSNames *[]string `json:"deniedDNSNames,omitempty"`
+
+	// +optional
+	DNSNames *PolicyDNSNames `json:"dnsNames,omitempty"`
+
+	// +optional
+	WildcardCertificates *PolicyWildcardCertificates `json:"wildcardCertificates,omitempty"`
+
 	// +optional
 	AllowedIPAddresses *[]string `json:"allowedIPAddresses,omitempty"`
 
+	// +optional
+	DeniedIPAddresses *[]string `json:"deniedIPAddresses,omitempty"`
+
+	// +optional
+	IPAddresses *PolicyIPAddresses `json:"ipAddresses,omitempty"`
+
 	// +optional
 	AllowedURIs *[]string `json:"allowedURIs,omitempty"`
 
+	// +optional
+	DeniedURIs *[]string `json:"deniedURIs,omitempty"`
+
 	// +optional
 	AllowedEmailAddresses *[]string `json:"allowedEmailAddresses,omitempty"`
 
 	// +optional
-	AllowedIssuers *[]cmmeta.ObjectReference `json:"allowedIssuer,omitempty"`
+	DeniedEmailAddresses *[]string `json:"deniedEmailAddresses,omitempty"`
+
+	// AllowedIssuers are the issuers a request may reference. A request is
+	// allowed if its issuer matches any of them.
+	// +optional
+	AllowedIssuers *[]PolicyIssuer `json:"allowedIssuer,omitempty"`
 
 	// +optional
 	AllowedIsCA *bool `json:"allowedIsCA,omitempty"`
@@ -81,8 +212,166 @@ type CertificateRequestPolicySpec struct {
 	// +optional
 	AllowedPrivateKey *PolicyPrivateKey `json:"allowedPrivateKey,omitempty"`
 
+	// RequiredAnnotations must each be matched by an annotation of the
+	// request.
 	// +optional
-	ExternalPolicyServers []string `json:"externalPolicyServers,omitempty"`
+	RequiredAnnotations *[]PolicyKeyValuePattern `json:"requiredAnnotations,omitempty"`
+
+	// RequiredLabels must each be matched by a label of the request.
+	// +optional
+	RequiredLabels *[]PolicyKeyValuePattern `json:"requiredLabels,omitempty"`
+
+	// AllowedAnnotations must match every annotation of the request. This
+	// includes the "cert-manager.io/*" annotations cert-manager sets on
+	// requests it creates for Certificates.
+	// +optional
+	AllowedAnnotations *[]PolicyKeyValuePattern `json:"allowedAnnotations,omitempty"`
+
+	// RequireOwningCertificate requires the request to be controlled by a
+	// Certificate in the same namespace, and its CSR, duration, usages, isCA
+	// and issuerRef to match the spec of that Certificate.
+	// +optional
+	RequireOwningCertificate *bool `json:"requireOwningCertificate,omitempty"`
+
+	// CertificateSecret constrains the Secret the owning Certificate of the
+	// request writes to. Requests which are not controlled by a Certificate
+	// are not evaluated against it.
+	// +optional
+	CertificateSecret *PolicyCertificateSecret `json:"certificateSecret,omitempty"`
+
+	// IssuerCAExpiry, if set, denies requests to CA issuers which would be
+	// valid for longer than the CA certificate of the issuer.
+	// +optional
+	IssuerCAExpiry *PolicyIssuerCAExpiry `json:"issuerCAExpiry,omitempty"`
+
+	// EnforceIssuerNameConstraints denies requests to CA issuers whose names
+	// fall outside the permitted, or inside the excluded, name constraints of
+	// the CA certificate of the issuer.
+	// +optional
+	EnforceIssuerNameConstraints *bool `json:"enforceIssuerNameConstraints,omitempty"`
+
+	// +optional
+	PodIdentity *PolicyPodIdentity `json:"podIdentity,omitempty"`
+
+	// +optional
+	NodeIdentity *PolicyNodeIdentity `json:"nodeIdentity,omitempty"`
+
+	// +optional
+	SPIFFE *PolicySPIFFE `json:"spiffe,omitempty"`
+}
+
+// PolicyRule is a set of constraints which applies to requests matching it.
+type PolicyRule struct {
+	// Name identifies the rule in denial messages.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Match selects the requests this rule applies to. An empty match
+	// selects every request.
+	// +optional
+	Match PolicyRuleMatch `json:"match,omitempty"`
+
+	PolicyConstraints `json:",inline"`
+}
+
+// PolicyRuleMatch selects requests. A request is selected if it matches every
+// set field.
+type PolicyRuleMatch struct {
+	// KeyAlgorithm matches the algorithm of the public key of the request.
+	// +optional
+	KeyAlgorithm *cmapi.PrivateKeyAlgorithm `json:"keyAlgorithm,omitempty"`
+
+	// IsCA matches whether the request is for a CA.
+	// +optional
+	IsCA *bool `json:"isCA,omitempty"`
+
+	// Issuer matches the issuer referenced by the request.
+	// +optional
+	Issuer *PolicyIssuer `json:"issuer,omitempty"`
+
+	// Usages matches requests with every one of these usages.
+	// +optional
+	Usages *[]cmapi.KeyUsage `json:"usages,omitempty"`
+
+	// Wildcard matches whether the request contains a wildcard DNS name.
+	// +optional
+	Wildcard *bool `json:"wildcard,omitempty"`
+
+	// NamespaceSelector matches the labels of the namespace of the request.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// CommonNameFormat is a syntax class that a common name must conform to.
+// +kubebuilder:validation:Enum=Hostname;Email;FreeText
+type CommonNameFormat string
+
+const (
+	// CommonNameFormatHostname requires the common name to be a valid DNS
+	// name, optionally with a leading wildcard label.
+	CommonNameFormatHostname CommonNameFormat = "Hostname"
+
+	// CommonNameFormatEmail requires the common name to be an email address.
+	CommonNameFormatEmail CommonNameFormat = "Email"
+
+	// CommonNameFormatFreeText requires the common name to only contain
+	// printable characters.
+	CommonNameFormatFreeText CommonNameFormat = "FreeText"
+)
+
+// PolicySubjects match the requester of a CertificateRequest. A requester
+// matches if it is any of the Users, in any of the Groups or any of the
+// ServiceAccounts, and matches every Extra matcher. If Users, Groups and
+// ServiceAccounts are all unset, any requester matching every Extra matcher
+// matches. Subjects without any matcher match no requester. Values accept
+// wildcards.
+type PolicySubjects struct {
+	// Mode is how Subjects combine with the RBAC "use" permission on this
+	// policy. Defaults to Additional.
+	// +optional
+	Mode *PolicySubjectsMode `json:"mode,omitempty"`
+
+	// +optional
+	Users *[]string `json:"users,omitempty"`
+
+	// +optional
+	Groups *[]string `json:"groups,omitempty"`
+
+	// +optional
+	ServiceAccounts *[]PolicySubjectServiceAccount `json:"serviceAccounts,omitempty"`
+
+	// Extra matches the extra user info of the requester, for example claims
+	// of an OIDC token.
+	// +optional
+	Extra []PolicySubjectExtra `json:"extra,omitempty"`
+}
+
+// PolicySubjectsMode is how Subjects combine with the RBAC "use" permission
+// on a policy.
+// +kubebuilder:validation:Enum=Additional;Alternative
+type PolicySubjectsMode string
+
+const (
+	// PolicySubjectsModeAdditional binds the policy to requesters which both
+	// have the "use" permission and match the Subjects.
+	PolicySubjectsModeAdditional PolicySubjectsMode = "Additional"
+
+	// PolicySubjectsModeAlternative binds the policy to requesters which
+	// either have the "use" permission or match the Subjects.
+	PolicySubjectsModeAlternative PolicySubjectsMode = "Alternative"
+)
+
+// PolicySubjectServiceAccount matches a ServiceAccount requester.
+type PolicySubjectServiceAccount struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// PolicySubjectExtra matches a key of the extra user info of the requester.
+// The requester must have a value for the key matching one of Values.
+type PolicySubjectExtra struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
 }
 
 type PolicyX509Subject struct {
@@ -102,6 +391,104 @@ type PolicyX509Subject struct {
 	AllowedPostalCodes *[]string `json:"allowedPostalCodes,omitempty"`
 	// +optional
 	AllowedSerialNumber *string `json:"allowedSerialNumber,omitempty"`
+	// +optional
+	AllowedDomainComponents *[]string `json:"allowedDomainComponents,omitempty"`
+	// +optional
+	AllowedUIDs *[]string `json:"allowedUIDs,omitempty"`
+	// +optional
+	AllowedTitles *[]string `json:"allowedTitles,omitempty"`
+	// AllowedEmailAddresses constrains the emailAddress subject attribute
+	// (1.2.840.113549.1.9.1), not email address SANs.
+	// +optional
+	AllowedEmailAddresses *[]string `json:"allowedEmailAddresses,omitempty"`
+
+	// AllowedDistinguishedNames is a list of patterns matched against the RFC
+	// 4514 string of the whole subject, for example "CN=*,OU=payments,O=Acme,C=GB".
+	// RDNs are matched in order and a wildcard never spans more than one RDN
+	// or attribute, so the subject must have exactly as many RDNs as the
+	// pattern, and each RDN the same attribute types. The attributes of a
+	// multi-valued RDN may be in any order, and attribute types are case
+	// insensitive.
+	// +optional
+	AllowedDistinguishedNames *[]string `json:"allowedDistinguishedNames,omitempty"`
+
+	// AllowedAttributes constrain the values of subject attributes by their
+	// OID, for attributes that have no dedicated field.
+	// +optional
+	AllowedAttributes []PolicyX509SubjectAttribute `json:"allowedAttributes,omitempty"`
+
+	// AllowUnknownSubjectAttributes, when false, denies requests whose subject
+	// contains an attribute that this policy does not constrain. Defaults to
+	// true.
+	// +optional
+	AllowUnknownSubjectAttributes *bool `json:"allowUnknownSubjectAttributes,omitempty"`
+
+	// AllowMultiValuedRDNs, when false, denies requests whose subject contains
+	// a relative distinguished name with more than one attribute. Defaults to
+	// true.
+	// +optional
+	AllowMultiValuedRDNs *bool `json:"allowMultiValuedRDNs,omitempty"`
+
+	// Denied subject attributes are patterns of values which are denied, even
+	// if allowed by the corresponding allowed field.
+	// +optional
+	DeniedOrganizations *[]string `json:"deniedOrganizations,omitempty"`
+	// +optional
+	DeniedCountries *[]string `json:"deniedCountries,omitempty"`
+	// +optional
+	DeniedOrganizationalUnits *[]string `json:"deniedOrganizationalUnits,omitempty"`
+	// +optional
+	DeniedLocalities *[]string `json:"deniedLocalities,omitempty"`
+	// +optional
+	DeniedProvinces *[]string `json:"deniedProvinces,omitempty"`
+	// +optional
+	DeniedStreetAddresses *[]string `json:"deniedStreetAddresses,omitempty"`
+	// +optional
+	DeniedPostalCodes *[]string `json:"deniedPostalCodes,omitempty"`
+	// +optional
+	DeniedSerialNumbers *[]string `json:"deniedSerialNumbers,omitempty"`
+	// +optional
+	DeniedDomainComponents *[]string `json:"deniedDomainComponents,omitempty"`
+	// +optional
+	DeniedUIDs *[]string `json:"deniedUIDs,omitempty"`
+	// +optional
+	DeniedTitles *[]string `json:"deniedTitles,omitempty"`
+	// +optional
+	DeniedEmailAddresses *[]string `json:"deniedEmailAddresses,omitempty"`
+}
+
+type PolicyX509SubjectAttribute struct {
+	// OID is the dot separated object identifier of the attribute type, for
+	// example "2.5.4.4" for surname.
+	OID string `json:"oid"`
+
+	// AllowedValues is the list of values that every occurrence of this
+	// attribute must match.
+	AllowedValues []string `json:"allowedValues"`
+}
+
+// PolicyIssuer matches the issuer referenced by a request. Issuers are
+// resolved in the namespace of the request, ClusterIssuers are cluster scoped.
+type PolicyIssuer struct {
+	// Name of the issuer. Accepts wildcards. If unset, issuers of any name
+	// match.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Kind of the issuer, for example Issuer or ClusterIssuer. Accepts
+	// wildcards. Defaults to Issuer, matching cert-manager's default for an
+	// unset issuer kind.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer. Accepts wildcards. Defaults to cert-manager.io.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Selector matches the labels of the Issuer or ClusterIssuer. Only
+	// supported for issuers of the cert-manager.io group.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 type PolicyPrivateKey struct {
@@ -116,6 +503,283 @@ type PolicyPrivateKey struct {
 	MaxSize *int `json:"allowedMaxSize,omitempty"`
 }
 
+// PolicyDNSNames constrain requested DNS names, independently of the patterns
+// in AllowedDNSNames. A leading wildcard label is removed from a DNS name
+// before it is evaluated.
+type PolicyDNSNames struct {
+	// DenyPublicSuffixes, when true, denies DNS names which are a public
+	// suffix, for example "co.uk".
+	// +optional
+	DenyPublicSuffixes *bool `json:"denyPublicSuffixes,omitempty"`
+
+	// DenyUnderPublicSuffix, when true, denies DNS names directly under a
+	// public suffix, for example "example.co.uk". Subdomains such as
+	// "foo.example.co.uk" are still allowed.
+	// +optional
+	DenyUnderPublicSuffix *bool `json:"denyUnderPublicSuffix,omitempty"`
+
+	// DenyReservedNames, when true, denies special-use and reserved DNS names
+	// such as "localhost", and names under ".local", ".internal", ".test",
+	// ".invalid", ".example", ".onion" and ".home.arpa".
+	// +optional
+	DenyReservedNames *bool `json:"denyReservedNames,omitempty"`
+
+	// AllowedRegistrableDomains, if set, requires every DNS name to fall under
+	// one of the given registrable domains, for example "example.co.uk".
+	// +optional
+	AllowedRegistrableDomains *[]string `json:"allowedRegistrableDomains,omitempty"`
+
+	// RejectConfusableNames, when true, denies internationalised DNS names
+	// with a label that mixes scripts, and DNS names which are visually
+	// confusable with, but not equal to, one of the ProtectedDomains or their
+	// subdomains.
+	// +optional
+	RejectConfusableNames *bool `json:"rejectConfusableNames,omitempty"`
+
+	// ProtectedDomains are domains that requested DNS names must not be
+	// visually confusable with, for example "paypal-internal.acme.com". Only
+	// used when RejectConfusableNames is true.
+	// +optional
+	ProtectedDomains []string `json:"protectedDomains,omitempty"`
+
+	// RequireBackingObjects, if set, requires every DNS name to be backed by
+	// an object of one of the given kinds in the namespace of the request:
+	// - Service: "<name>.<namespace>.svc" or
+	//   "<name>.<namespace>.svc.<cluster domain>".
+	// - Ingress: a spec.rules[].host or spec.tls[].hosts entry.
+	// - HTTPRoute: a spec.hostnames entry.
+	// - Gateway: a spec.listeners[].hostname entry.
+	// A wildcard host of an Ingress covers a single label, whereas a wildcard
+	// hostname of an HTTPRoute or Gateway covers any number of labels.
+	// +optional
+	RequireBackingObjects []DNSNameBackingObject `json:"requireBackingObjects,omitempty"`
+}
+
+// DNSNameBackingObject is a kind of object which may back a DNS name.
+// +kubebuilder:validation:Enum=Service;Ingress;HTTPRoute;Gateway
+type DNSNameBackingObject string
+
+const (
+	DNSNameBackingObjectService   DNSNameBackingObject = "Service"
+	DNSNameBackingObjectIngress   DNSNameBackingObject = "Ingress"
+	DNSNameBackingObjectHTTPRoute DNSNameBackingObject = "HTTPRoute"
+	DNSNameBackingObjectGateway   DNSNameBackingObject = "Gateway"
+)
+
+// PolicyIPAddresses constrain requested IP addresses by their category,
+// independently of the patterns in AllowedIPAddresses.
+type PolicyIPAddresses struct {
+	// AllowedCategories, if set, requires every category of every IP address
+	// to be one of the given categories.
+	// +optional
+	AllowedCategories *[]IPAddressCategory `json:"allowedCategories,omitempty"`
+
+	// DeniedCategories denies IP addresses with any of the given categories.
+	// +optional
+	DeniedCategories *[]IPAddressCategory `json:"deniedCategories,omitempty"`
+}
+
+// IPAddressCategory is a category of an IP address. An IP address may belong
+// to more than one category; a cloud metadata address also belongs to the
+// category of its range, for example 169.254.169.254 is both CloudMetadata and
+// LinkLocal. Every IP address belongs to at least one category.
+// +kubebuilder:validation:Enum=Unspecified;Loopback;CloudMetadata;LinkLocal;Multicast;Private;Shared;Public;Other
+type IPAddressCategory string
+
+const (
+	// IPAddressCategoryUnspecified is 0.0.0.0 or ::.
+	IPAddressCategoryUnspecified IPAddressCategory = "Unspecified"
+
+	// IPAddressCategoryLoopback is 127.0.0.0/8 or ::1.
+	IPAddressCategoryLoopback IPAddressCategory = "Loopback"
+
+	// IPAddressCategoryCloudMetadata are the instance metadata endpoints of
+	// cloud providers, such as 169.254.169.254.
+	IPAddressCategoryCloudMetadata IPAddressCategory = "CloudMetadata"
+
+	// IPAddressCategoryLinkLocal is 169.254.0.0/16 or fe80::/10.
+	IPAddressCategoryLinkLocal IPAddressCategory = "LinkLocal"
+
+	// IPAddressCategoryMulticast is 224.0.0.0/4 or ff00::/8.
+	IPAddressCategoryMulticast IPAddressCategory = "Multicast"
+
+	// IPAddressCategoryPrivate is an RFC 1918 or RFC 4193 (ULA) address.
+	IPAddressCategoryPrivate IPAddressCategory = "Private"
+
+	// IPAddressCategoryShared is an RFC 6598 shared address space (carrier
+	// grade NAT) address, 100.64.0.0/10.
+	IPAddressCategoryShared IPAddressCategory = "Shared"
+
+	// IPAddressCategoryPublic is any other global unicast address.
+	IPAddressCategoryPublic IPAddressCategory = "Public"
+
+	// IPAddressCategoryOther is any address not in another category, such as
+	// the IPv4 broadcast address.
+	IPAddressCategoryOther IPAddressCategory = "Other"
+)
+
+// PolicyWildcardCertificates constrain requested wildcard DNS names,
+// independently of the patterns in AllowedDNSNames.
+type PolicyWildcardCertificates struct {
+	// Allowed, when false, denies requests containing a wildcard DNS name.
+	// Defaults to true.
+	// +optional
+	Allowed *bool `json:"allowed,omitempty"`
+
+	// MinLabels is the minimum number of labels to the right of the wildcard
+	// label. For example, a value of 2 allows "*.example.com" but denies
+	// "*.com".
+	// +optional
+	MinLabels *int `json:"minLabels,omitempty"`
+
+	// DenyUnderPublicSuffix, when true, denies wildcards directly under a
+	// public suffix, for example "*.co.uk" or "*.github.io".
+	// +optional
+	DenyUnderPublicSuffix *bool `json:"denyUnderPublicSuffix,omitempty"`
+
+	// MaxDuration is the maximum duration of a request containing a wildcard
+	// DNS name. Values are inclusive.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+}
+
+// PolicyCertificateSecret constrains the Secret a Certificate writes to.
+type PolicyCertificateSecret struct {
+	// AllowedSecretNames are the allowed values of spec.secretName. Accepts
+	// wildcards.
+	// +optional
+	AllowedSecretNames *[]string `json:"allowedSecretNames,omitempty"`
+
+	// AllowedLabels are the allowed labels of spec.secretTemplate.
+	// +optional
+	AllowedLabels *[]PolicyKeyValuePattern `json:"allowedLabels,omitempty"`
+
+	// AllowedAnnotations are the allowed annotations of spec.secretTemplate.
+	// +optional
+	AllowedAnnotations *[]PolicyKeyValuePattern `json:"allowedAnnotations,omitempty"`
+
+	// AllowedKeystores are the keystores which may be created alongside the
+	// Secret.
+	// +optional
+	AllowedKeystores *[]CertificateKeystore `json:"allowedKeystores,omitempty"`
+
+	// AllowedAdditionalOutputFormats are the allowed types of
+	// spec.additionalOutputFormats, for example "CombinedPEM" or "DER".
+	// +optional
+	AllowedAdditionalOutputFormats *[]string `json:"allowedAdditionalOutputFormats,omitempty"`
+}
+
+// PolicyKeyValuePattern matches a key and value, such as a label or an
+// annotation. Key and Value accept wildcards. If Value is unset, any value
+// matches.
+type PolicyKeyValuePattern struct {
+	Key string `json:"key"`
+
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// CertificateKeystore is a keystore a Certificate may create.
+// +kubebuilder:validation:Enum=JKS;PKCS12
+type CertificateKeystore string
+
+const (
+	CertificateKeystoreJKS    CertificateKeystore = "JKS"
+	CertificateKeystorePKCS12 CertificateKeystore = "PKCS12"
+)
+
+// PolicyIssuerCAExpiry denies requests whose duration would extend past the
+// expiry of the CA certificate of their issuer. Only CA issuers are
+// evaluated, as the CA certificate of other issuers cannot be read.
+type PolicyIssuerCAExpiry struct {
+	// SafetyMargin is the duration before the expiry of the CA certificate
+	// that requests must expire by. Defaults to 0.
+	// +optional
+	SafetyMargin *metav1.Duration `json:"safetyMargin,omitempty"`
+}
+
+// PolicyPodIdentity binds requests made with a Pod's ServiceAccount token, for
+// example by csi-driver, to that Pod. The Pod is resolved from the pod-name
+// and pod-uid claims of the token, and must run as the requesting
+// ServiceAccount. Every requested IP address must be an IP of the Pod, and
+// every requested DNS name must be a DNS name of the Pod, derived from its
+// hostname and subdomain, or a DNS name of a Service selecting the Pod.
+type PolicyPodIdentity struct {
+	// AllowServiceDNSNames, when false, only allows the DNS names derived from
+	// the hostname and subdomain of the Pod. Defaults to true.
+	// +optional
+	AllowServiceDNSNames *bool `json:"allowServiceDNSNames,omitempty"`
+}
+
+// PolicyNodeIdentity binds requests made by a kubelet to its Node, following
+// the conventions of the Kubernetes kubelet serving CSR approver. The
+// requester must be "system:node:<name>" in the "system:nodes" group, the
+// Common Name must be "system:node:<name>" and the only Organization must be
+// "system:nodes". At least one IP address or DNS name must be requested, and
+// every requested IP address and DNS name must be an address of the Node. No
+// URIs or email addresses may be requested. The request must have the server
+// auth usage, may only additionally have the digital signature and key
+// encipherment usages, and may not be for a CA.
+type PolicyNodeIdentity struct {
+	// AllowedAddressTypes are the types of Node address which may be
+	// requested. Defaults to all types.
+	// +optional
+	AllowedAddressTypes *[]corev1.NodeAddressType `json:"allowedAddressTypes,omitempty"`
+}
+
+// PolicySPIFFE binds requests made by a ServiceAccount to the SPIFFE ID of
+// that ServiceAccount. The request must be made by a ServiceAccount in the
+// namespace of the request, and contain exactly one URI of the form
+// "spiffe://<trustDomain>/ns/<namespace>/sa/<name>". The request may only
+// contain usages of client and server authentication, and the key usages they
+// require, and may not be for a CA.
+type PolicySPIFFE struct {
+	// TrustDomain is the SPIFFE trust domain of the SPIFFE ID. It may only
+	// contain lowercase letters, digits, dots, dashes and underscores.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[a-z0-9._-]+$`
+	TrustDomain string `json:"trustDomain"`
+
+	// AllowAdditionalSANs, when true, allows the request to contain DNS names,
+	// IP addresses and email addresses alongside the SPIFFE ID. Defaults to
+	// false.
+	// +optional
+	AllowAdditionalSANs *bool `json:"allowAdditionalSANs,omitempty"`
+}
+
+// PolicyLimits bound the size of a request. Values are inclusive (i.e. a max
+// value of 10 will accept 10 DNS names). Defaulted limits are enforced even if
+// no limits are defined.
+type PolicyLimits struct {
+	// +optional
+	MaxDNSNames *int `json:"maxDNSNames,omitempty"`
+	// +optional
+	MaxIPAddresses *int `json:"maxIPAddresses,omitempty"`
+	// +optional
+	MaxURIs *int `json:"maxURIs,omitempty"`
+	// +optional
+	MaxEmailAddresses *int `json:"maxEmailAddresses,omitempty"`
+	// MaxTotalSANs is the maximum number of DNS names, IP addresses, URIs and
+	// email addresses combined. Defaults to 100.
+	// +optional
+	MaxTotalSANs *int `json:"maxTotalSANs,omitempty"`
+
+	// Defaults to 63 (RFC 1035).
+	// +optional
+	MaxDNSLabelLength *int `json:"maxDNSLabelLength,omitempty"`
+	// Defaults to 253 (RFC 1035).
+	// +optional
+	MaxDNSNameLength *int `json:"maxDNSNameLength,omitempty"`
+	// Defaults to 64 (ub-common-name, RFC 5280).
+	// +optional
+	MaxCommonNameLength *int `json:"maxCommonNameLength,omitempty"`
+
+	// MaxRequestSize is the maximum size in bytes of the PEM encoded CSR.
+	// Defaults to 65536.
+	// +optional
+	MaxRequestSize *int `json:"maxRequestSize,omitempty"`
+}
+
 type CertificateRequestPolicyStatus struct {
 	// +optional
 	Conditions []CertificateRequestPolicyCondition `json:"conditions,omitempty"`
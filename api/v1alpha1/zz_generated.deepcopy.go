@@ -22,7 +22,7 @@ package v1alpha1
 
 import (
 	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -107,6 +107,155 @@ func (in *CertificateRequestPolicyList) DeepCopyObject() runtime.Object {
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPolicySpec) {
+	*out = *in
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = new(PolicySubjects)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tier != nil {
+		in, out := &in.Tier, &out.Tier
+		*out = new(int32)
+		**out = **in
+	}
+	in.PolicyConstraints.DeepCopyInto(&out.PolicyConstraints)
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Denial != nil {
+		in, out := &in.Denial, &out.Denial
+		*out = new(PolicyDenial)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(PolicyLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalPolicyServers != nil {
+		in, out := &in.ExternalPolicyServers, &out.ExternalPolicyServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySpec.
+func (in *CertificateRequestPolicySpec) DeepCopy() *CertificateRequestPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRequestPolicyStatus) DeepCopyInto(out *CertificateRequestPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]CertificateRequestPolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyStatus.
+func (in *CertificateRequestPolicyStatus) DeepCopy() *CertificateRequestPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRequestPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyCertificateSecret) DeepCopyInto(out *PolicyCertificateSecret) {
+	*out = *in
+	if in.AllowedSecretNames != nil {
+		in, out := &in.AllowedSecretNames, &out.AllowedSecretNames
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedLabels != nil {
+		in, out := &in.AllowedLabels, &out.AllowedLabels
+		*out = new([]PolicyKeyValuePattern)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]PolicyKeyValuePattern, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.AllowedAnnotations != nil {
+		in, out := &in.AllowedAnnotations, &out.AllowedAnnotations
+		*out = new([]PolicyKeyValuePattern)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]PolicyKeyValuePattern, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.AllowedKeystores != nil {
+		in, out := &in.AllowedKeystores, &out.AllowedKeystores
+		*out = new([]CertificateKeystore)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]CertificateKeystore, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedAdditionalOutputFormats != nil {
+		in, out := &in.AllowedAdditionalOutputFormats, &out.AllowedAdditionalOutputFormats
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyCertificateSecret.
+func (in *PolicyCertificateSecret) DeepCopy() *PolicyCertificateSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyCertificateSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyConstraintDenial) DeepCopyInto(out *PolicyConstraintDenial) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyConstraintDenial.
+func (in *PolicyConstraintDenial) DeepCopy() *PolicyConstraintDenial {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyConstraintDenial)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyConstraints) DeepCopyInto(out *PolicyConstraints) {
 	*out = *in
 	if in.AllowedSubject != nil {
 		in, out := &in.AllowedSubject, &out.AllowedSubject
@@ -118,6 +267,30 @@ func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPoli
 		*out = new(string)
 		**out = **in
 	}
+	if in.DeniedCommonNames != nil {
+		in, out := &in.DeniedCommonNames, &out.DeniedCommonNames
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.CommonNameMustBeSAN != nil {
+		in, out := &in.CommonNameMustBeSAN, &out.CommonNameMustBeSAN
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CommonNameFormat != nil {
+		in, out := &in.CommonNameFormat, &out.CommonNameFormat
+		*out = new(CommonNameFormat)
+		**out = **in
+	}
+	if in.RequireExplicitDuration != nil {
+		in, out := &in.RequireExplicitDuration, &out.RequireExplicitDuration
+		*out = new(bool)
+		**out = **in
+	}
 	if in.MinDuration != nil {
 		in, out := &in.MinDuration, &out.MinDuration
 		*out = new(v1.Duration)
@@ -137,6 +310,25 @@ func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPoli
 			copy(*out, *in)
 		}
 	}
+	if in.DeniedDNSNames != nil {
+		in, out := &in.DeniedDNSNames, &out.DeniedDNSNames
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = new(PolicyDNSNames)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WildcardCertificates != nil {
+		in, out := &in.WildcardCertificates, &out.WildcardCertificates
+		*out = new(PolicyWildcardCertificates)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AllowedIPAddresses != nil {
 		in, out := &in.AllowedIPAddresses, &out.AllowedIPAddresses
 		*out = new([]string)
@@ -146,6 +338,20 @@ func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPoli
 			copy(*out, *in)
 		}
 	}
+	if in.DeniedIPAddresses != nil {
+		in, out := &in.DeniedIPAddresses, &out.DeniedIPAddresses
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = new(PolicyIPAddresses)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AllowedURIs != nil {
 		in, out := &in.AllowedURIs, &out.AllowedURIs
 		*out = new([]string)
@@ -155,6 +361,15 @@ func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPoli
 			copy(*out, *in)
 		}
 	}
+	if in.DeniedURIs != nil {
+		in, out := &in.DeniedURIs, &out.DeniedURIs
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
 	if in.AllowedEmailAddresses != nil {
 		in, out := &in.AllowedEmailAddresses, &out.AllowedEmailAddresses
 		*out = new([]string)
@@ -164,22 +379,463 @@ func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPoli
 			copy(*out, *in)
 		}
 	}
+	if in.DeniedEmailAddresses != nil {
+		in, out := &in.DeniedEmailAddresses, &out.DeniedEmailAddresses
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
 	if in.AllowedIssuers != nil {
 		in, out := &in.AllowedIssuers, &out.AllowedIssuers
-		*out = new([]metav1.ObjectReference)
+		*out = new([]PolicyIssuer)
 		if **in != nil {
 			in, out := *in, *out
-			*out = make([]metav1.ObjectReference, len(*in))
+			*out = make([]PolicyIssuer, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.AllowedIsCA != nil {
+		in, out := &in.AllowedIsCA, &out.AllowedIsCA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedUsages != nil {
+		in, out := &in.AllowedUsages, &out.AllowedUsages
+		*out = new([]certmanagerv1.KeyUsage)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]certmanagerv1.KeyUsage, len(*in))
 			copy(*out, *in)
 		}
 	}
-	if in.AllowedIsCA != nil {
-		in, out := &in.AllowedIsCA, &out.AllowedIsCA
+	if in.AllowedPrivateKey != nil {
+		in, out := &in.AllowedPrivateKey, &out.AllowedPrivateKey
+		*out = new(PolicyPrivateKey)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequiredAnnotations != nil {
+		in, out := &in.RequiredAnnotations, &out.RequiredAnnotations
+		*out = new([]PolicyKeyValuePattern)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]PolicyKeyValuePattern, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = new([]PolicyKeyValuePattern)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]PolicyKeyValuePattern, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.AllowedAnnotations != nil {
+		in, out := &in.AllowedAnnotations, &out.AllowedAnnotations
+		*out = new([]PolicyKeyValuePattern)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]PolicyKeyValuePattern, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.RequireOwningCertificate != nil {
+		in, out := &in.RequireOwningCertificate, &out.RequireOwningCertificate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CertificateSecret != nil {
+		in, out := &in.CertificateSecret, &out.CertificateSecret
+		*out = new(PolicyCertificateSecret)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IssuerCAExpiry != nil {
+		in, out := &in.IssuerCAExpiry, &out.IssuerCAExpiry
+		*out = new(PolicyIssuerCAExpiry)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnforceIssuerNameConstraints != nil {
+		in, out := &in.EnforceIssuerNameConstraints, &out.EnforceIssuerNameConstraints
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PodIdentity != nil {
+		in, out := &in.PodIdentity, &out.PodIdentity
+		*out = new(PolicyPodIdentity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeIdentity != nil {
+		in, out := &in.NodeIdentity, &out.NodeIdentity
+		*out = new(PolicyNodeIdentity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SPIFFE != nil {
+		in, out := &in.SPIFFE, &out.SPIFFE
+		*out = new(PolicySPIFFE)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyConstraints.
+func (in *PolicyConstraints) DeepCopy() *PolicyConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyDNSNames) DeepCopyInto(out *PolicyDNSNames) {
+	*out = *in
+	if in.DenyPublicSuffixes != nil {
+		in, out := &in.DenyPublicSuffixes, &out.DenyPublicSuffixes
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DenyUnderPublicSuffix != nil {
+		in, out := &in.DenyUnderPublicSuffix, &out.DenyUnderPublicSuffix
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DenyReservedNames != nil {
+		in, out := &in.DenyReservedNames, &out.DenyReservedNames
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedRegistrableDomains != nil {
+		in, out := &in.AllowedRegistrableDomains, &out.AllowedRegistrableDomains
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.RejectConfusableNames != nil {
+		in, out := &in.RejectConfusableNames, &out.RejectConfusableNames
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProtectedDomains != nil {
+		in, out := &in.ProtectedDomains, &out.ProtectedDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequireBackingObjects != nil {
+		in, out := &in.RequireBackingObjects, &out.RequireBackingObjects
+		*out = make([]DNSNameBackingObject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyDNSNames.
+func (in *PolicyDNSNames) DeepCopy() *PolicyDNSNames {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyDNSNames)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyDenial) DeepCopyInto(out *PolicyDenial) {
+	*out = *in
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = make([]PolicyConstraintDenial, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyDenial.
+func (in *PolicyDenial) DeepCopy() *PolicyDenial {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyDenial)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyIPAddresses) DeepCopyInto(out *PolicyIPAddresses) {
+	*out = *in
+	if in.AllowedCategories != nil {
+		in, out := &in.AllowedCategories, &out.AllowedCategories
+		*out = new([]IPAddressCategory)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]IPAddressCategory, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedCategories != nil {
+		in, out := &in.DeniedCategories, &out.DeniedCategories
+		*out = new([]IPAddressCategory)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]IPAddressCategory, len(*in))
+			copy(*out, *in)
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyIPAddresses.
+func (in *PolicyIPAddresses) DeepCopy() *PolicyIPAddresses {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyIPAddresses)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyIssuer) DeepCopyInto(out *PolicyIssuer) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyIssuer.
+func (in *PolicyIssuer) DeepCopy() *PolicyIssuer {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyIssuer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyIssuerCAExpiry) DeepCopyInto(out *PolicyIssuerCAExpiry) {
+	*out = *in
+	if in.SafetyMargin != nil {
+		in, out := &in.SafetyMargin, &out.SafetyMargin
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyIssuerCAExpiry.
+func (in *PolicyIssuerCAExpiry) DeepCopy() *PolicyIssuerCAExpiry {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyIssuerCAExpiry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyKeyValuePattern) DeepCopyInto(out *PolicyKeyValuePattern) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyKeyValuePattern.
+func (in *PolicyKeyValuePattern) DeepCopy() *PolicyKeyValuePattern {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyKeyValuePattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyLimits) DeepCopyInto(out *PolicyLimits) {
+	*out = *in
+	if in.MaxDNSNames != nil {
+		in, out := &in.MaxDNSNames, &out.MaxDNSNames
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxIPAddresses != nil {
+		in, out := &in.MaxIPAddresses, &out.MaxIPAddresses
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxURIs != nil {
+		in, out := &in.MaxURIs, &out.MaxURIs
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxEmailAddresses != nil {
+		in, out := &in.MaxEmailAddresses, &out.MaxEmailAddresses
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxTotalSANs != nil {
+		in, out := &in.MaxTotalSANs, &out.MaxTotalSANs
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxDNSLabelLength != nil {
+		in, out := &in.MaxDNSLabelLength, &out.MaxDNSLabelLength
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxDNSNameLength != nil {
+		in, out := &in.MaxDNSNameLength, &out.MaxDNSNameLength
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxCommonNameLength != nil {
+		in, out := &in.MaxCommonNameLength, &out.MaxCommonNameLength
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxRequestSize != nil {
+		in, out := &in.MaxRequestSize, &out.MaxRequestSize
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyLimits.
+func (in *PolicyLimits) DeepCopy() *PolicyLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyNodeIdentity) DeepCopyInto(out *PolicyNodeIdentity) {
+	*out = *in
+	if in.AllowedAddressTypes != nil {
+		in, out := &in.AllowedAddressTypes, &out.AllowedAddressTypes
+		*out = new([]corev1.NodeAddressType)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]corev1.NodeAddressType, len(*in))
+			copy(*out, *in)
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyNodeIdentity.
+func (in *PolicyNodeIdentity) DeepCopy() *PolicyNodeIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyNodeIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyPodIdentity) DeepCopyInto(out *PolicyPodIdentity) {
+	*out = *in
+	if in.AllowServiceDNSNames != nil {
+		in, out := &in.AllowServiceDNSNames, &out.AllowServiceDNSNames
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyPodIdentity.
+func (in *PolicyPodIdentity) DeepCopy() *PolicyPodIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyPodIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyPrivateKey) DeepCopyInto(out *PolicyPrivateKey) {
+	*out = *in
+	if in.AllowedAlgorithm != nil {
+		in, out := &in.AllowedAlgorithm, &out.AllowedAlgorithm
+		*out = new(certmanagerv1.PrivateKeyAlgorithm)
+		**out = **in
+	}
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyPrivateKey.
+func (in *PolicyPrivateKey) DeepCopy() *PolicyPrivateKey {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyPrivateKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyRule) DeepCopyInto(out *PolicyRule) {
+	*out = *in
+	in.Match.DeepCopyInto(&out.Match)
+	in.PolicyConstraints.DeepCopyInto(&out.PolicyConstraints)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRule.
+func (in *PolicyRule) DeepCopy() *PolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyRuleMatch) DeepCopyInto(out *PolicyRuleMatch) {
+	*out = *in
+	if in.KeyAlgorithm != nil {
+		in, out := &in.KeyAlgorithm, &out.KeyAlgorithm
+		*out = new(certmanagerv1.PrivateKeyAlgorithm)
+		**out = **in
+	}
+	if in.IsCA != nil {
+		in, out := &in.IsCA, &out.IsCA
 		*out = new(bool)
 		**out = **in
 	}
-	if in.AllowedUsages != nil {
-		in, out := &in.AllowedUsages, &out.AllowedUsages
+	if in.Issuer != nil {
+		in, out := &in.Issuer, &out.Issuer
+		*out = new(PolicyIssuer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Usages != nil {
+		in, out := &in.Usages, &out.Usages
 		*out = new([]certmanagerv1.KeyUsage)
 		if **in != nil {
 			in, out := *in, *out
@@ -187,76 +843,168 @@ func (in *CertificateRequestPolicySpec) DeepCopyInto(out *CertificateRequestPoli
 			copy(*out, *in)
 		}
 	}
-	if in.AllowedPrivateKey != nil {
-		in, out := &in.AllowedPrivateKey, &out.AllowedPrivateKey
-		*out = new(PolicyPrivateKey)
+	if in.Wildcard != nil {
+		in, out := &in.Wildcard, &out.Wildcard
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.ExternalPolicyServers != nil {
-		in, out := &in.ExternalPolicyServers, &out.ExternalPolicyServers
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRuleMatch.
+func (in *PolicyRuleMatch) DeepCopy() *PolicyRuleMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyRuleMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySPIFFE) DeepCopyInto(out *PolicySPIFFE) {
+	*out = *in
+	if in.AllowAdditionalSANs != nil {
+		in, out := &in.AllowAdditionalSANs, &out.AllowAdditionalSANs
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySPIFFE.
+func (in *PolicySPIFFE) DeepCopy() *PolicySPIFFE {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicySPIFFE)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySubjectExtra) DeepCopyInto(out *PolicySubjectExtra) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicySpec.
-func (in *CertificateRequestPolicySpec) DeepCopy() *CertificateRequestPolicySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySubjectExtra.
+func (in *PolicySubjectExtra) DeepCopy() *PolicySubjectExtra {
 	if in == nil {
 		return nil
 	}
-	out := new(CertificateRequestPolicySpec)
+	out := new(PolicySubjectExtra)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CertificateRequestPolicyStatus) DeepCopyInto(out *CertificateRequestPolicyStatus) {
+func (in *PolicySubjectServiceAccount) DeepCopyInto(out *PolicySubjectServiceAccount) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]CertificateRequestPolicyCondition, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySubjectServiceAccount.
+func (in *PolicySubjectServiceAccount) DeepCopy() *PolicySubjectServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicySubjectServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySubjects) DeepCopyInto(out *PolicySubjects) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(PolicySubjectsMode)
+		**out = **in
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = new([]PolicySubjectServiceAccount)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]PolicySubjectServiceAccount, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = make([]PolicySubjectExtra, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRequestPolicyStatus.
-func (in *CertificateRequestPolicyStatus) DeepCopy() *CertificateRequestPolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySubjects.
+func (in *PolicySubjects) DeepCopy() *PolicySubjects {
 	if in == nil {
 		return nil
 	}
-	out := new(CertificateRequestPolicyStatus)
+	out := new(PolicySubjects)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PolicyPrivateKey) DeepCopyInto(out *PolicyPrivateKey) {
+func (in *PolicyWildcardCertificates) DeepCopyInto(out *PolicyWildcardCertificates) {
 	*out = *in
-	if in.AllowedAlgorithm != nil {
-		in, out := &in.AllowedAlgorithm, &out.AllowedAlgorithm
-		*out = new(certmanagerv1.PrivateKeyAlgorithm)
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = new(bool)
 		**out = **in
 	}
-	if in.MinSize != nil {
-		in, out := &in.MinSize, &out.MinSize
+	if in.MinLabels != nil {
+		in, out := &in.MinLabels, &out.MinLabels
 		*out = new(int)
 		**out = **in
 	}
-	if in.MaxSize != nil {
-		in, out := &in.MaxSize, &out.MaxSize
-		*out = new(int)
+	if in.DenyUnderPublicSuffix != nil {
+		in, out := &in.DenyUnderPublicSuffix, &out.DenyUnderPublicSuffix
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyPrivateKey.
-func (in *PolicyPrivateKey) DeepCopy() *PolicyPrivateKey {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyWildcardCertificates.
+func (in *PolicyWildcardCertificates) DeepCopy() *PolicyWildcardCertificates {
 	if in == nil {
 		return nil
 	}
-	out := new(PolicyPrivateKey)
+	out := new(PolicyWildcardCertificates)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -332,6 +1080,176 @@ func (in *PolicyX509Subject) DeepCopyInto(out *PolicyX509Subject) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AllowedDomainComponents != nil {
+		in, out := &in.AllowedDomainComponents, &out.AllowedDomainComponents
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedUIDs != nil {
+		in, out := &in.AllowedUIDs, &out.AllowedUIDs
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedTitles != nil {
+		in, out := &in.AllowedTitles, &out.AllowedTitles
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedEmailAddresses != nil {
+		in, out := &in.AllowedEmailAddresses, &out.AllowedEmailAddresses
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedDistinguishedNames != nil {
+		in, out := &in.AllowedDistinguishedNames, &out.AllowedDistinguishedNames
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.AllowedAttributes != nil {
+		in, out := &in.AllowedAttributes, &out.AllowedAttributes
+		*out = make([]PolicyX509SubjectAttribute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowUnknownSubjectAttributes != nil {
+		in, out := &in.AllowUnknownSubjectAttributes, &out.AllowUnknownSubjectAttributes
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowMultiValuedRDNs != nil {
+		in, out := &in.AllowMultiValuedRDNs, &out.AllowMultiValuedRDNs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeniedOrganizations != nil {
+		in, out := &in.DeniedOrganizations, &out.DeniedOrganizations
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedCountries != nil {
+		in, out := &in.DeniedCountries, &out.DeniedCountries
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedOrganizationalUnits != nil {
+		in, out := &in.DeniedOrganizationalUnits, &out.DeniedOrganizationalUnits
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedLocalities != nil {
+		in, out := &in.DeniedLocalities, &out.DeniedLocalities
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedProvinces != nil {
+		in, out := &in.DeniedProvinces, &out.DeniedProvinces
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedStreetAddresses != nil {
+		in, out := &in.DeniedStreetAddresses, &out.DeniedStreetAddresses
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedPostalCodes != nil {
+		in, out := &in.DeniedPostalCodes, &out.DeniedPostalCodes
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedSerialNumbers != nil {
+		in, out := &in.DeniedSerialNumbers, &out.DeniedSerialNumbers
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedDomainComponents != nil {
+		in, out := &in.DeniedDomainComponents, &out.DeniedDomainComponents
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedUIDs != nil {
+		in, out := &in.DeniedUIDs, &out.DeniedUIDs
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedTitles != nil {
+		in, out := &in.DeniedTitles, &out.DeniedTitles
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.DeniedEmailAddresses != nil {
+		in, out := &in.DeniedEmailAddresses, &out.DeniedEmailAddresses
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyX509Subject.
@@ -343,3 +1261,23 @@ func (in *PolicyX509Subject) DeepCopy() *PolicyX509Subject {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyX509SubjectAttribute) DeepCopyInto(out *PolicyX509SubjectAttribute) {
+	*out = *in
+	if in.AllowedValues != nil {
+		in, out := &in.AllowedValues, &out.AllowedValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyX509SubjectAttribute.
+func (in *PolicyX509SubjectAttribute) DeepCopy() *PolicyX509SubjectAttribute {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyX509SubjectAttribute)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -23,6 +23,8 @@ import (
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,22 +34,30 @@ import (
 // CertificateRequest reconciles a CertificateRequestPolicy object
 type CRController struct {
 	client.Client
-	log logr.Logger
+	log      logr.Logger
+	recorder record.EventRecorder
 
 	policy *policy.Policy
 }
 
-func New(log logr.Logger, client client.Client, policy *policy.Policy) *CRController {
+func New(log logr.Logger, client client.Client, recorder record.EventRecorder, policy *policy.Policy) *CRController {
 	return &CRController{
-		Client: client,
-		log:    log.WithName("certificate-requests"),
-		policy: policy,
+		Client:   client,
+		log:      log.WithName("certificate-requests"),
+		recorder: recorder,
+		policy:   policy,
 	}
 }
 
 //+kubebuilder:rbac:groups=policy.cert-manager.io,resources=certificaterequestpolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=policy.cert-manager.io,resources=certificaterequestpolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=policy.cert-manager.io,resources=certificaterequestpolicies/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=namespaces;nodes;pods;services,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways;httproutes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates;clusterissuers;issuers,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -86,6 +96,12 @@ func (c *CRController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	if ok {
+		c.recorder.Event(cr, corev1.EventTypeNormal, "Approved", reason)
+	} else {
+		c.recorder.Event(cr, corev1.EventTypeWarning, "Denied", reason)
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -1,12 +1,14 @@
 module github.com/cert-manager/policy-approver
 
-go 1.15
+go 1.18
 
 require (
 	github.com/go-logr/logr v0.3.0
 	github.com/jetstack/cert-manager v1.2.0
 	github.com/onsi/ginkgo v1.14.1
 	github.com/onsi/gomega v1.10.2
+	golang.org/x/net v0.0.0-20200822124328-c89045814202
+	golang.org/x/text v0.3.3
 	k8s.io/api v0.19.2
 	k8s.io/apimachinery v0.19.2
 	k8s.io/client-go v0.19.2
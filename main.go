@@ -18,13 +18,16 @@ package main
 
 import (
 	"flag"
+	"io/ioutil"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/api"
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -35,6 +38,10 @@ import (
 	policycertmanageriov1alpha1 "github.com/cert-manager/policy-approver/api/v1alpha1"
 	"github.com/cert-manager/policy-approver/controllers"
 	"github.com/cert-manager/policy-approver/policy"
+	"github.com/cert-manager/policy-approver/policy/checks/confusables"
+	"github.com/cert-manager/policy-approver/policy/checks/publicsuffix"
+	"github.com/cert-manager/policy-approver/policy/requester"
+	"github.com/cert-manager/policy-approver/webhooks"
 )
 
 var (
@@ -53,11 +60,43 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var publicSuffixListFile string
+	var confusablesFile string
+	var clusterDomain string
+	var defaultDuration time.Duration
+	var clusterResourceNamespace string
+	var requesterFromCertificate bool
+	var requesterSigningKeyFile string
+	var certManagerUsername string
+	var combination string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&publicSuffixListFile, "public-suffix-list-file", "",
+		"Path to a public_suffix_list.dat file to use instead of the snapshot embedded in the binary.")
+	flag.StringVar(&confusablesFile, "confusables-file", "",
+		"Path to a Unicode TR39 confusables.txt file to use instead of the copy embedded in the binary.")
+	flag.StringVar(&clusterDomain, "cluster-domain", "cluster.local",
+		"The DNS domain of the cluster, used to derive the DNS names of Services and Pods.")
+	flag.DurationVar(&defaultDuration, "default-duration", cmapiv1.DefaultCertificateDuration,
+		"The duration requests without a duration are evaluated against, unless their ClusterIssuer has the "+
+			policy.IssuerDefaultDurationAnnotationKey+" annotation.")
+	flag.StringVar(&clusterResourceNamespace, "cluster-resource-namespace", "cert-manager",
+		"The namespace of the Secrets referenced by ClusterIssuers, as configured for cert-manager.")
+	flag.BoolVar(&requesterFromCertificate, "requester-from-certificate", false,
+		"Evaluate CertificateRequests owned by a Certificate against the requester which last modified the "+
+			"Certificate spec, as stamped by the Certificate admission webhook.")
+	flag.StringVar(&requesterSigningKeyFile, "requester-signing-key-file", "",
+		"Path to the key used to sign and verify the requester annotation of Certificates. "+
+			"Required if --requester-from-certificate is set.")
+	flag.StringVar(&certManagerUsername, "cert-manager-username", "system:serviceaccount:cert-manager:cert-manager",
+		"The username of the cert-manager controller. Only CertificateRequests created by this user are "+
+			"evaluated against the requester of their owning Certificate.")
+	flag.StringVar(&combination, "policy-combination", string(policy.CombinationAnyOf),
+		"How the policies bound to a request are combined, one of AnyOf, AllOf or Layered. May be overridden "+
+			"per Namespace with the "+policy.NamespaceCombinationAnnotationKey+" annotation.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -70,6 +109,28 @@ func main() {
 		panic(err)
 	}
 
+	policyCombination, err := policy.ParseCombination(combination)
+	if err != nil {
+		setupLog.Error(err, "invalid policy combination")
+		os.Exit(1)
+	}
+
+	if len(publicSuffixListFile) > 0 {
+		list, err := publicsuffix.ParseFile(publicSuffixListFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load public suffix list", "path", publicSuffixListFile)
+			os.Exit(1)
+		}
+		publicsuffix.SetDefault(list)
+	}
+
+	if len(confusablesFile) > 0 {
+		if err := confusables.LoadFile(confusablesFile); err != nil {
+			setupLog.Error(err, "unable to load confusables", "path", confusablesFile)
+			os.Exit(1)
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -91,7 +152,35 @@ func main() {
 	//	setupLog.Error(err, "unable to create controller", "controller", "CertificateRequestPolicy")
 	//	os.Exit(1)
 	//}
-	c := controllers.New(ctrl.Log, mgr.GetClient(), policy.New(mgr.GetClient()))
+	var requesterSigner *requester.Signer
+	if requesterFromCertificate {
+		key, err := ioutil.ReadFile(requesterSigningKeyFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read requester signing key", "path", requesterSigningKeyFile)
+			os.Exit(1)
+		}
+		requesterSigner, err = requester.NewSigner(key)
+		if err != nil {
+			setupLog.Error(err, "invalid requester signing key", "path", requesterSigningKeyFile)
+			os.Exit(1)
+		}
+
+		w := webhooks.NewCertificateRequester(ctrl.Log, requesterSigner)
+		if err := w.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Certificate")
+			os.Exit(1)
+		}
+	}
+
+	c := controllers.New(ctrl.Log, mgr.GetClient(), mgr.GetEventRecorderFor("policy-approver"), policy.New(mgr.GetClient(), policy.Options{
+		ClusterDomain:            clusterDomain,
+		DefaultDuration:          defaultDuration,
+		ClusterResourceNamespace: clusterResourceNamespace,
+		SecretReader:             mgr.GetAPIReader(),
+		RequesterSigner:          requesterSigner,
+		CertManagerUsername:      certManagerUsername,
+		Combination:              policyCombination,
+	}))
 	if err := c.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CertificateRequestPolicy")
 		os.Exit(1)
@@ -0,0 +1,279 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cert-manager/policy-approver/policy/requester"
+)
+
+// owningCertificate returns the Certificate which is the controller of the
+// CertificateRequest. Returns nil if the CertificateRequest is not controlled
+// by a Certificate, or the Certificate no longer exists.
+func (p *Policy) owningCertificate(ctx context.Context, cr *cmapi.CertificateRequest) (*cmapi.Certificate, error) {
+	ref := metav1.GetControllerOf(cr)
+	if ref == nil || ref.Kind != cmapi.CertificateKind {
+		return nil, nil
+	}
+	if gv, err := schema.ParseGroupVersion(ref.APIVersion); err != nil || gv.Group != cmapi.SchemeGroupVersion.Group {
+		return nil, nil
+	}
+
+	cert := new(cmapi.Certificate)
+	if err := p.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: ref.Name}, cert); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if cert.UID != ref.UID {
+		return nil, nil
+	}
+
+	return cert, nil
+}
+
+// resolveRequester returns the CertificateRequest with its requester replaced
+// by the signed requester of its owning Certificate. CertificateRequests which
+// were not created by cert-manager, or are not owned by a Certificate, are
+// returned unchanged. If the owning Certificate does not have a valid
+// requester annotation, returns a reason the requester could not be resolved.
+func (p *Policy) resolveRequester(ctx context.Context, cr *cmapi.CertificateRequest) (*cmapi.CertificateRequest, string, error) {
+	if p.opts.RequesterSigner == nil {
+		return cr, "", nil
+	}
+
+	// Only cert-manager creates requests on behalf of Certificates. Any other
+	// requester keeps its own identity, whatever its owner references.
+	if len(p.opts.CertManagerUsername) == 0 || cr.Spec.Username != p.opts.CertManagerUsername {
+		return cr, "", nil
+	}
+
+	cert, err := p.owningCertificate(ctx, cr)
+	if err != nil || cert == nil {
+		return cr, "", err
+	}
+
+	value, ok := cert.Annotations[requester.AnnotationKey]
+	if !ok {
+		return nil, fmt.Sprintf("Owning Certificate %q has no %q annotation", cert.Name, requester.AnnotationKey), nil
+	}
+
+	identity, err := p.opts.RequesterSigner.Verify(cert.Namespace, cert.Name, value)
+	if err != nil {
+		return nil, fmt.Sprintf("Owning Certificate %q has an invalid %q annotation: %s", cert.Name, requester.AnnotationKey, err), nil
+	}
+
+	cr = cr.DeepCopy()
+	cr.Spec.Username = identity.Username
+	cr.Spec.UID = identity.UID
+	cr.Spec.Groups = identity.Groups
+	cr.Spec.Extra = identity.Extra
+
+	return cr, "", nil
+}
+
+// evaluateOwningCertificate will add an error if the request is not
+// controlled by a Certificate, or does not match the spec of that Certificate.
+func (p *Policy) evaluateOwningCertificate(ctx context.Context, el *field.ErrorList, path *field.Path, require *bool, cr *cmapi.CertificateRequest, csr *x509.CertificateRequest) error {
+	// Allow all
+	if require == nil || !*require {
+		return nil
+	}
+
+	cert, err := p.owningCertificate(ctx, cr)
+	if err != nil {
+		return err
+	}
+	if cert == nil {
+		*el = append(*el, field.Invalid(path, metav1.GetControllerOf(cr), "request is not controlled by a Certificate in its namespace"))
+		return nil
+	}
+
+	mismatchAt := func(fldPath *field.Path, requested, expected interface{}) {
+		*el = append(*el, field.Invalid(fldPath, requested,
+			fmt.Sprintf("does not match Certificate %q: %v", cert.Name, expected)))
+	}
+	mismatch := func(name string, requested, expected interface{}) {
+		mismatchAt(path.Child(name), requested, expected)
+	}
+
+	if !apiequality.Semantic.DeepEqual(cr.Spec.Duration, cert.Spec.Duration) {
+		mismatch("duration", cr.Spec.Duration, cert.Spec.Duration)
+	}
+	if !sameStrings(usageStrings(cr.Spec.Usages), usageStrings(cert.Spec.Usages)) {
+		mismatch("usages", cr.Spec.Usages, cert.Spec.Usages)
+	}
+	if cr.Spec.IsCA != cert.Spec.IsCA {
+		mismatch("isCA", cr.Spec.IsCA, cert.Spec.IsCA)
+	}
+	if cr.Spec.IssuerRef != cert.Spec.IssuerRef {
+		mismatch("issuerRef", cr.Spec.IssuerRef, cert.Spec.IssuerRef)
+	}
+
+	if csr.Subject.CommonName != cert.Spec.CommonName {
+		mismatch("commonName", csr.Subject.CommonName, cert.Spec.CommonName)
+	}
+	if !sameStrings(csr.DNSNames, cert.Spec.DNSNames) {
+		mismatch("dnsNames", csr.DNSNames, cert.Spec.DNSNames)
+	}
+	if !sameStrings(ipStrings(csr.IPAddresses), normaliseIPs(cert.Spec.IPAddresses)) {
+		mismatch("ipAddresses", ipStrings(csr.IPAddresses), cert.Spec.IPAddresses)
+	}
+	uris := uriStrings(csr.URIs)
+	if !sameStrings(uris, cert.Spec.URIs) {
+		mismatch("uris", uris, cert.Spec.URIs)
+	}
+	if !sameStrings(csr.EmailAddresses, cert.Spec.EmailAddresses) {
+		mismatch("emailAddresses", csr.EmailAddresses, cert.Spec.EmailAddresses)
+	}
+
+	subject := cert.Spec.Subject
+	if subject == nil {
+		subject = new(cmapi.X509Subject)
+	}
+	for _, attr := range []struct {
+		name      string
+		requested []string
+		expected  []string
+	}{
+		{"organizations", csr.Subject.Organization, subject.Organizations},
+		{"countries", csr.Subject.Country, subject.Countries},
+		{"organizationalUnits", csr.Subject.OrganizationalUnit, subject.OrganizationalUnits},
+		{"localities", csr.Subject.Locality, subject.Localities},
+		{"provinces", csr.Subject.Province, subject.Provinces},
+		{"streetAddresses", csr.Subject.StreetAddress, subject.StreetAddresses},
+		{"postalCodes", csr.Subject.PostalCode, subject.PostalCodes},
+	} {
+		if !sameStrings(attr.requested, attr.expected) {
+			mismatch(attr.name, attr.requested, attr.expected)
+		}
+	}
+	if csr.Subject.SerialNumber != subject.SerialNumber {
+		mismatch("serialNumber", csr.Subject.SerialNumber, subject.SerialNumber)
+	}
+
+	alg, size, err := parsePublicKey(csr.PublicKey)
+	if err != nil {
+		return err
+	}
+	expAlg, expSize := certificateKeyAlgorithmAndSize(cert.Spec.PrivateKey)
+	if alg != expAlg {
+		mismatchAt(path.Child("privateKey", "algorithm"), alg, expAlg)
+	} else if size != expSize {
+		mismatchAt(path.Child("privateKey", "size"), size, expSize)
+	}
+
+	return nil
+}
+
+// certificateKeyAlgorithmAndSize returns the private key algorithm and size of
+// the Certificate private key, applying the defaults of cert-manager.
+func certificateKeyAlgorithmAndSize(key *cmapi.CertificatePrivateKey) (cmapi.PrivateKeyAlgorithm, int) {
+	alg, size := cmapi.RSAKeyAlgorithm, 0
+	if key != nil {
+		if len(key.Algorithm) > 0 {
+			alg = key.Algorithm
+		}
+		size = key.Size
+	}
+
+	if size == 0 {
+		switch alg {
+		case cmapi.RSAKeyAlgorithm:
+			size = 2048
+		case cmapi.ECDSAKeyAlgorithm:
+			size = 256
+		}
+	}
+
+	return alg, size
+}
+
+// sameStrings returns true if both lists contain the same set of strings.
+func sameStrings(a, b []string) bool {
+	return apiequality.Semantic.DeepEqual(uniqueSorted(a), uniqueSorted(b))
+}
+
+// uniqueSorted returns the sorted, de-duplicated strings of the list.
+func uniqueSorted(list []string) []string {
+	set := make(map[string]bool)
+	for _, s := range list {
+		set[s] = true
+	}
+	out := make([]string, 0, len(set))
+	for s := range set {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// usageStrings returns the usages as strings.
+func usageStrings(usages []cmapi.KeyUsage) []string {
+	var out []string
+	for _, usage := range usages {
+		out = append(out, string(usage))
+	}
+	return out
+}
+
+// ipStrings returns the IP addresses as strings.
+func ipStrings(ips []net.IP) []string {
+	var out []string
+	for _, ip := range ips {
+		out = append(out, ip.String())
+	}
+	return out
+}
+
+// uriStrings returns the URIs as strings.
+func uriStrings(uris []*url.URL) []string {
+	var out []string
+	for _, uri := range uris {
+		out = append(out, uri.String())
+	}
+	return out
+}
+
+// normaliseIPs returns the canonical form of each IP address, leaving any
+// which cannot be parsed unchanged.
+func normaliseIPs(ips []string) []string {
+	var out []string
+	for _, s := range ips {
+		if ip := net.ParseIP(s); ip != nil {
+			s = ip.String()
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cert-manager/policy-approver/policy/requester"
+)
+
+func TestResolveRequester(t *testing.T) {
+	const certManagerUsername = "system:serviceaccount:cert-manager:cert-manager"
+
+	signer, err := requester.NewSigner(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := signer.Sign("test", "test", requester.Identity{Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := cmapi.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cert := &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "test",
+			Name:        "test",
+			UID:         "cert-uid",
+			Annotations: map[string]string{requester.AnnotationKey: value},
+		},
+	}
+	unsigned := &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "unsigned", UID: "unsigned-uid"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cert, unsigned).Build()
+
+	controlledBy := func(cert *cmapi.Certificate) []metav1.OwnerReference {
+		return []metav1.OwnerReference{*metav1.NewControllerRef(cert, cmapi.SchemeGroupVersion.WithKind(cmapi.CertificateKind))}
+	}
+
+	tests := map[string]struct {
+		username    string
+		owners      []metav1.OwnerReference
+		expUsername string
+		expReason   bool
+	}{
+		"created by cert-manager: signed requester": {
+			username:    certManagerUsername,
+			owners:      controlledBy(cert),
+			expUsername: "alice",
+		},
+		"created by cert-manager without an owning Certificate: unchanged": {
+			username:    certManagerUsername,
+			expUsername: certManagerUsername,
+		},
+		"created by cert-manager for an unsigned Certificate: reason": {
+			username:  certManagerUsername,
+			owners:    controlledBy(unsigned),
+			expReason: true,
+		},
+		"forged owner reference: requester unchanged": {
+			username:    "mallory",
+			owners:      controlledBy(cert),
+			expUsername: "mallory",
+		},
+		"forged owner reference to an unsigned Certificate: requester unchanged": {
+			username:    "mallory",
+			owners:      controlledBy(unsigned),
+			expUsername: "mallory",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test-1", OwnerReferences: test.owners},
+				Spec:       cmapi.CertificateRequestSpec{Username: test.username},
+			}
+
+			p := New(c, Options{RequesterSigner: signer, CertManagerUsername: certManagerUsername})
+			resolved, reason, err := p.resolveRequester(context.TODO(), cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (len(reason) > 0) != test.expReason {
+				t.Fatalf("unexpected reason: %q", reason)
+			}
+			if test.expReason {
+				return
+			}
+			if resolved.Spec.Username != test.expUsername {
+				t.Errorf("unexpected username: exp=%q got=%q", test.expUsername, resolved.Spec.Username)
+			}
+		})
+	}
+}
+
+func TestEvaluateOwningCertificate(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsa2048, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseSpec := func() cmapi.CertificateSpec {
+		return cmapi.CertificateSpec{
+			CommonName:     "example.com",
+			DNSNames:       []string{"example.com", "www.example.com"},
+			IPAddresses:    []string{"10.0.0.1"},
+			URIs:           []string{"spiffe://example.com/web"},
+			EmailAddresses: []string{"admin@example.com"},
+			Subject:        &cmapi.X509Subject{Organizations: []string{"Example"}, SerialNumber: "1234"},
+			Duration:       &metav1.Duration{Duration: time.Hour},
+			Usages:         []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageServerAuth},
+			IssuerRef:      cmmeta.ObjectReference{Name: "ca", Kind: cmapi.IssuerKind},
+			PrivateKey:     &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+		}
+	}
+	baseTemplate := func() *x509.CertificateRequest {
+		return &x509.CertificateRequest{
+			Subject:        pkix.Name{CommonName: "example.com", Organization: []string{"Example"}, SerialNumber: "1234"},
+			DNSNames:       []string{"www.example.com", "example.com"},
+			IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+			URIs:           []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/web"}},
+			EmailAddresses: []string{"admin@example.com"},
+		}
+	}
+
+	parseCSR := func(template *x509.CertificateRequest, key crypto.Signer) *x509.CertificateRequest {
+		block, _ := pem.Decode(mustCSRWithKey(t, template, key))
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return csr
+	}
+
+	tests := map[string]struct {
+		notControlled bool
+		cert          func(*cmapi.CertificateSpec)
+		cr            func(*cmapi.CertificateRequestSpec)
+		csr           func(*x509.CertificateRequest)
+		key           crypto.Signer
+		expErrs       int
+	}{
+		"matching Certificate should allow": {
+			expErrs: 0,
+		},
+		"request not controlled by a Certificate should deny": {
+			notControlled: true,
+			expErrs:       1,
+		},
+		"duration drift should deny": {
+			cr:      func(spec *cmapi.CertificateRequestSpec) { spec.Duration = &metav1.Duration{Duration: 2 * time.Hour} },
+			expErrs: 1,
+		},
+		"usages drift should deny": {
+			cr:      func(spec *cmapi.CertificateRequestSpec) { spec.Usages = append(spec.Usages, cmapi.UsageClientAuth) },
+			expErrs: 1,
+		},
+		"isCA drift should deny": {
+			cr:      func(spec *cmapi.CertificateRequestSpec) { spec.IsCA = true },
+			expErrs: 1,
+		},
+		"issuerRef drift should deny": {
+			cr:      func(spec *cmapi.CertificateRequestSpec) { spec.IssuerRef.Name = "other" },
+			expErrs: 1,
+		},
+		"common name drift should deny": {
+			csr:     func(csr *x509.CertificateRequest) { csr.Subject.CommonName = "www.example.com" },
+			expErrs: 1,
+		},
+		"DNS names drift should deny": {
+			csr:     func(csr *x509.CertificateRequest) { csr.DNSNames = append(csr.DNSNames, "other.example.com") },
+			expErrs: 1,
+		},
+		"IP addresses drift should deny": {
+			csr:     func(csr *x509.CertificateRequest) { csr.IPAddresses = []net.IP{net.ParseIP("10.0.0.2")} },
+			expErrs: 1,
+		},
+		"URIs drift should deny": {
+			csr:     func(csr *x509.CertificateRequest) { csr.URIs = nil },
+			expErrs: 1,
+		},
+		"email addresses drift should deny": {
+			csr:     func(csr *x509.CertificateRequest) { csr.EmailAddresses = []string{"root@example.com"} },
+			expErrs: 1,
+		},
+		"organizations drift should deny": {
+			csr:     func(csr *x509.CertificateRequest) { csr.Subject.Organization = []string{"Other"} },
+			expErrs: 1,
+		},
+		"serial number drift should deny": {
+			csr:     func(csr *x509.CertificateRequest) { csr.Subject.SerialNumber = "5678" },
+			expErrs: 1,
+		},
+		"private key algorithm drift should deny": {
+			key:     rsa2048,
+			expErrs: 1,
+		},
+		"private key size drift should deny": {
+			key:     p384,
+			expErrs: 1,
+		},
+		"private key of the specified size should allow": {
+			cert:    func(spec *cmapi.CertificateSpec) { spec.PrivateKey.Size = 384 },
+			key:     p384,
+			expErrs: 0,
+		},
+		"default private key should allow an RSA 2048 key": {
+			cert:    func(spec *cmapi.CertificateSpec) { spec.PrivateKey = nil },
+			key:     rsa2048,
+			expErrs: 0,
+		},
+		"larger RSA private key size should deny": {
+			cert: func(spec *cmapi.CertificateSpec) {
+				spec.PrivateKey = &cmapi.CertificatePrivateKey{Algorithm: cmapi.RSAKeyAlgorithm, Size: 4096}
+			},
+			key:     rsa2048,
+			expErrs: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cert := &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test", UID: "cert-uid"},
+				Spec:       baseSpec(),
+			}
+			if test.cert != nil {
+				test.cert(&cert.Spec)
+			}
+
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test-1"},
+				Spec: cmapi.CertificateRequestSpec{
+					Duration:  cert.Spec.Duration,
+					Usages:    cert.Spec.Usages,
+					IsCA:      cert.Spec.IsCA,
+					IssuerRef: cert.Spec.IssuerRef,
+				},
+			}
+			if !test.notControlled {
+				cr.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(cert, cmapi.SchemeGroupVersion.WithKind(cmapi.CertificateKind))}
+			}
+			if test.cr != nil {
+				test.cr(&cr.Spec)
+			}
+
+			template := baseTemplate()
+			if test.csr != nil {
+				test.csr(template)
+			}
+			key := test.key
+			if key == nil {
+				key = p256
+			}
+
+			scheme := runtime.NewScheme()
+			if err := cmapi.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cert).Build()
+
+			yes := true
+			var el field.ErrorList
+			if err := New(c, Options{}).evaluateOwningCertificate(context.TODO(), &el, field.NewPath("spec"), &yes, cr, parseCSR(template, key)); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
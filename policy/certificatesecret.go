@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+	"github.com/cert-manager/policy-approver/policy/checks/wildcard"
+)
+
+// evaluateCertificateSecret will add an error if the owning Certificate of
+// the request writes to a Secret which is not allowed. The Certificate is read
+// as an unstructured object, since the secret template and additional output
+// formats are not part of all Certificate API versions this is built against.
+func (p *Policy) evaluateCertificateSecret(ctx context.Context, el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyCertificateSecret, cr *cmapi.CertificateRequest) error {
+	// Allow all
+	if policy == nil {
+		return nil
+	}
+
+	owner, err := p.owningCertificate(ctx, cr)
+	if err != nil || owner == nil {
+		return err
+	}
+
+	cert := new(unstructured.Unstructured)
+	cert.SetGroupVersionKind(cmapi.SchemeGroupVersion.WithKind(cmapi.CertificateKind))
+	if err := p.Get(ctx, client.ObjectKey{Namespace: owner.Namespace, Name: owner.Name}, cert); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if policy.AllowedSecretNames != nil {
+		secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+		if !wildcard.Contains(*policy.AllowedSecretNames, secretName) {
+			*el = append(*el, field.Invalid(path.Child("allowedSecretNames"), secretName,
+				fmt.Sprintf("secret name of Certificate %q is not allowed", owner.Name)))
+		}
+	}
+
+	if policy.AllowedLabels != nil {
+		labels, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "secretTemplate", "labels")
+		evaluateKeyValues(el, path.Child("allowedLabels"), *policy.AllowedLabels, labels)
+	}
+
+	if policy.AllowedAnnotations != nil {
+		annotations, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "secretTemplate", "annotations")
+		evaluateKeyValues(el, path.Child("allowedAnnotations"), *policy.AllowedAnnotations, annotations)
+	}
+
+	if policy.AllowedKeystores != nil {
+		for _, keystore := range []struct {
+			field string
+			kind  cmpolicy.CertificateKeystore
+		}{
+			{"jks", cmpolicy.CertificateKeystoreJKS},
+			{"pkcs12", cmpolicy.CertificateKeystorePKCS12},
+		} {
+			create, _, _ := unstructured.NestedBool(cert.Object, "spec", "keystores", keystore.field, "create")
+			if create && !containsKeystore(*policy.AllowedKeystores, keystore.kind) {
+				*el = append(*el, field.Invalid(path.Child("allowedKeystores"), keystore.kind,
+					fmt.Sprintf("keystore of Certificate %q is not allowed", owner.Name)))
+			}
+		}
+	}
+
+	if policy.AllowedAdditionalOutputFormats != nil {
+		formats, _, _ := unstructured.NestedSlice(cert.Object, "spec", "additionalOutputFormats")
+		for _, format := range formats {
+			format, ok := format.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			formatType, _, _ := unstructured.NestedString(format, "type")
+			if !containsString(*policy.AllowedAdditionalOutputFormats, formatType) {
+				*el = append(*el, field.Invalid(path.Child("allowedAdditionalOutputFormats"), formatType,
+					fmt.Sprintf("additional output format of Certificate %q is not allowed", owner.Name)))
+			}
+		}
+	}
+
+	return nil
+}
+
+// evaluateKeyValues will add an error for each key value pair which does not
+// match any of the patterns.
+func evaluateKeyValues(el *field.ErrorList, path *field.Path, patterns []cmpolicy.PolicyKeyValuePattern, kvs map[string]string) {
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !matchesKeyValue(patterns, k, kvs[k]) {
+			*el = append(*el, field.Invalid(path, fmt.Sprintf("%s=%s", k, kvs[k]), "not allowed"))
+		}
+	}
+}
+
+// matchesKeyValue returns true if the key value pair matches any of the
+// patterns.
+func matchesKeyValue(patterns []cmpolicy.PolicyKeyValuePattern, key, value string) bool {
+	for _, pattern := range patterns {
+		if wildcard.Matchs(pattern.Key, key) && (pattern.Value == nil || wildcard.Matchs(*pattern.Value, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsKeystore returns true if the keystore is in the list.
+func containsKeystore(keystores []cmpolicy.CertificateKeystore, keystore cmpolicy.CertificateKeystore) bool {
+	for _, k := range keystores {
+		if k == keystore {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// unstructuredCertificateClient serves unstructured Gets of the Certificate
+// from its object, since the typed fake client drops fields which are not part
+// of the Certificate API version it is built against.
+type unstructuredCertificateClient struct {
+	client.Client
+	object map[string]interface{}
+}
+
+func (c unstructuredCertificateClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = runtime.DeepCopyJSON(c.object)
+		return nil
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestEvaluateCertificateSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := cmapi.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cert := &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test", UID: "cert-uid"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cert).Build()
+
+	controlledBy := []metav1.OwnerReference{*metav1.NewControllerRef(cert, cmapi.SchemeGroupVersion.WithKind(cmapi.CertificateKind))}
+
+	value := "team-*"
+	tests := map[string]struct {
+		policy        *cmpolicy.PolicyCertificateSecret
+		notControlled bool
+		spec          map[string]interface{}
+		expErrs       int
+	}{
+		"no policy should allow": {
+			policy:  nil,
+			spec:    map[string]interface{}{"secretName": "other"},
+			expErrs: 0,
+		},
+		"request not controlled by a Certificate should allow": {
+			policy:        &cmpolicy.PolicyCertificateSecret{AllowedSecretNames: &[]string{"test-*"}},
+			notControlled: true,
+			spec:          map[string]interface{}{"secretName": "other"},
+			expErrs:       0,
+		},
+		"allowed secret name should allow": {
+			policy:  &cmpolicy.PolicyCertificateSecret{AllowedSecretNames: &[]string{"test-*"}},
+			spec:    map[string]interface{}{"secretName": "test-tls"},
+			expErrs: 0,
+		},
+		"secret name which is not allowed should deny": {
+			policy:  &cmpolicy.PolicyCertificateSecret{AllowedSecretNames: &[]string{"test-*"}},
+			spec:    map[string]interface{}{"secretName": "other-tls"},
+			expErrs: 1,
+		},
+		"allowed secret template should allow": {
+			policy: &cmpolicy.PolicyCertificateSecret{
+				AllowedLabels:      &[]cmpolicy.PolicyKeyValuePattern{{Key: "team", Value: &value}},
+				AllowedAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: "example.com/*"}},
+			},
+			spec: map[string]interface{}{
+				"secretTemplate": map[string]interface{}{
+					"labels":      map[string]interface{}{"team": "team-a"},
+					"annotations": map[string]interface{}{"example.com/owner": "a"},
+				},
+			},
+			expErrs: 0,
+		},
+		"secret template labels and annotations which are not allowed should deny": {
+			policy: &cmpolicy.PolicyCertificateSecret{
+				AllowedLabels:      &[]cmpolicy.PolicyKeyValuePattern{{Key: "team", Value: &value}},
+				AllowedAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: "example.com/*"}},
+			},
+			spec: map[string]interface{}{
+				"secretTemplate": map[string]interface{}{
+					"labels":      map[string]interface{}{"team": "other", "app": "web"},
+					"annotations": map[string]interface{}{"other.com/owner": "a"},
+				},
+			},
+			expErrs: 3,
+		},
+		"allowed keystore should allow": {
+			policy: &cmpolicy.PolicyCertificateSecret{AllowedKeystores: &[]cmpolicy.CertificateKeystore{cmpolicy.CertificateKeystorePKCS12}},
+			spec: map[string]interface{}{
+				"keystores": map[string]interface{}{
+					"jks":    map[string]interface{}{"create": false},
+					"pkcs12": map[string]interface{}{"create": true},
+				},
+			},
+			expErrs: 0,
+		},
+		"keystore which is not allowed should deny": {
+			policy: &cmpolicy.PolicyCertificateSecret{AllowedKeystores: &[]cmpolicy.CertificateKeystore{cmpolicy.CertificateKeystorePKCS12}},
+			spec: map[string]interface{}{
+				"keystores": map[string]interface{}{
+					"jks": map[string]interface{}{"create": true},
+				},
+			},
+			expErrs: 1,
+		},
+		"allowed additional output format should allow": {
+			policy: &cmpolicy.PolicyCertificateSecret{AllowedAdditionalOutputFormats: &[]string{"CombinedPEM"}},
+			spec: map[string]interface{}{
+				"additionalOutputFormats": []interface{}{map[string]interface{}{"type": "CombinedPEM"}},
+			},
+			expErrs: 0,
+		},
+		"additional output format which is not allowed should deny": {
+			policy: &cmpolicy.PolicyCertificateSecret{AllowedAdditionalOutputFormats: &[]string{"CombinedPEM"}},
+			spec: map[string]interface{}{
+				"additionalOutputFormats": []interface{}{
+					map[string]interface{}{"type": "CombinedPEM"},
+					map[string]interface{}{"type": "DER"},
+				},
+			},
+			expErrs: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test-1"},
+			}
+			if !test.notControlled {
+				cr.OwnerReferences = controlledBy
+			}
+
+			object := map[string]interface{}{
+				"apiVersion": cmapi.SchemeGroupVersion.String(),
+				"kind":       cmapi.CertificateKind,
+				"metadata":   map[string]interface{}{"namespace": "test", "name": "test", "uid": "cert-uid"},
+				"spec":       test.spec,
+			}
+
+			var el field.ErrorList
+			p := New(unstructuredCertificateClient{Client: c, object: object}, Options{})
+			if err := p.evaluateCertificateSecret(context.TODO(), &el, field.NewPath("spec", "certificateSecret"), test.policy, cr); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
@@ -17,17 +17,16 @@ limitations under the License.
 package policy
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
-	"crypto/x509/pkix"
+	"encoding/asn1"
 	"errors"
-	"net"
-	"net/url"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
@@ -36,95 +35,217 @@ import (
 
 var (
 	parseKeyError = errors.New("failed to parse public key")
+
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
 )
 
-// check holds the json path to this field, the policy enforced on the field,
-// and the requested value.
-type check struct {
-	path    string
-	policy  interface{}
-	request interface{}
+// basicConstraints is the ASN.1 structure of the basic constraints extension.
+type basicConstraints struct {
+	IsCA       bool `asn1:"optional"`
+	MaxPathLen int  `asn1:"optional,default:-1"`
 }
 
+const (
+	// Upper bounds defined by RFC 1035 and RFC 5280, used when a limits block
+	// is not defined or does not set a value.
+	defaultMaxDNSLabelLength   = 63
+	defaultMaxDNSNameLength    = 253
+	defaultMaxCommonNameLength = 64
+
+	// Upper bounds on the size of a request, used when a limits block is not
+	// defined or does not set a value, so that requests are never pattern
+	// matched unbounded.
+	defaultMaxTotalSANs   = 100
+	defaultMaxRequestSize = 64 * 1024
+)
+
 // EvaluateCertificateRequest evaluates whether the given CertificateRequest
 // passes the CertificateRequestPolicy. If this request is denied by this
 // policy, 'el' will be populated. An error signals that the policy couldn't be
 // evaluated to completion.
-func EvaluateCertificateRequest(el *field.ErrorList, policy *cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) error {
+func (p *Policy) EvaluateCertificateRequest(ctx context.Context, el *field.ErrorList, policy *cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) error {
 	path := field.NewPath("spec")
 
+	// A policy with an invalid denial would otherwise silently give the
+	// default reason for the constraints it was meant to customise.
+	n := len(*el)
+	validateDenial(el, path.Child("denial"), policy.Spec.Denial)
+	if len(*el) > n {
+		return nil
+	}
+
+	// Enforce limits before decoding the request or performing any pattern
+	// matching, so that oversized requests are rejected cheaply.
+	limits := policy.Spec.Limits
+	if limits == nil {
+		limits = new(cmpolicy.PolicyLimits)
+	}
+	checks.MaxSize(el, path.Child("limits", "maxRequestSize"), intOrDefault(limits.MaxRequestSize, defaultMaxRequestSize), len(cr.Spec.Request))
+	if len(*el) > n {
+		return nil
+	}
+
 	// decode CSR from CertificateRequest
 	csr, err := utilpki.DecodeX509CertificateRequestBytes(cr.Spec.Request)
 	if err != nil {
 		return err
 	}
 
-	// Add x509 subject and private key checks.
-	subjchecks := evaluatex509Subject(el, path.Child("allowedSubject"), policy.Spec.AllowedSubject, csr.Subject)
-	pkchecks, err := evaluatePrivateKey(el, path.Child("allowedPrivateKey"), policy.Spec.AllowedPrivateKey, csr)
+	if !evaluateLimits(el, path.Child("limits"), limits, csr) {
+		return nil
+	}
+
+	// The subject fields of the decoded request only hold the last of any
+	// duplicate attribute, so requests with duplicate single valued
+	// attributes would otherwise be evaluated against only one of them.
+	rdns, err := decodeSubject(csr.RawSubject)
 	if err != nil {
 		return err
 	}
+	if !evaluateSingleValuedAttributes(el, path, rdns) {
+		return nil
+	}
 
-	// Adds checks for all fields in CertificateRequestPolicy spec
-	spec := append(subjchecks, []check{
-		{"allowedCommonName", policy.Spec.AllowedCommonName, csr.Subject.CommonName},
-		{"allowedMinDuration", policy.Spec.MinDuration, cr.Spec.Duration},
-		{"allowedMaxDuration", policy.Spec.MaxDuration, cr.Spec.Duration},
-		{"allowedDNSNames", policy.Spec.AllowedDNSNames, csr.DNSNames},
-		{"allowedIPAddresses", policy.Spec.AllowedIPAddresses, csr.IPAddresses},
-		{"allowedURIs", policy.Spec.AllowedURIs, csr.URIs},
-		{"allowedEmailAddresses", policy.Spec.AllowedEmailAddresses, csr.EmailAddresses},
-		{"allowedIssuers", policy.Spec.AllowedIssuers, cr.Spec.IssuerRef},
-		{"allowedIsCA", policy.Spec.AllowedIsCA, cr.Spec.IsCA},
-		{"allowedKeyUsages", policy.Spec.AllowedUsages, cr.Spec.Usages},
-	}...)
-	spec = append(spec, pkchecks...)
-
-	checks.MinDuration(el, path, policy.Spec.MinDuration, cr.Spec.Duration)
-	checks.MaxDuration(el, path, policy.Spec.MinDuration, cr.Spec.Duration)
-
-	// Use the type of the policy and request value to infer which check to
-	// perform.
-	for _, check := range spec {
-		switch check.policy.(type) {
-
-		case *[]string:
-			policy := check.policy.(*[]string)
-			switch check.request.(type) {
-			case string:
-				checks.Strings(el, path.Child(check.path), policy, check.request.(string))
-			case []string:
-				checks.StringSlice(el, path.Child(check.path), policy, check.request.([]string))
-			case []net.IP:
-				checks.IPSlice(el, path.Child(check.path), policy, check.request.([]net.IP))
-			case []*url.URL:
-				checks.URLSlice(el, path.Child(check.path), policy, check.request.([]*url.URL))
-			}
-
-		case *string:
-			checks.String(el, path.Child(check.path), check.policy.(*string), check.request.(string))
-
-		case *[]cmmeta.ObjectReference:
-			checks.ObjectReference(el, path.Child(check.path), check.policy.(*[]cmmeta.ObjectReference), check.request.(cmmeta.ObjectReference))
-
-		case *[]cmapi.KeyUsage:
-			checks.KeyUsageSlice(el, path.Child(check.path), check.policy.(*[]cmapi.KeyUsage), check.request.([]cmapi.KeyUsage))
-		case *[]cmapi.PrivateKeyAlgorithm:
-			checks.String(el, path.Child(check.path), check.policy.(*string), check.request.(string))
-		}
+	// Resolve the duration the request will be issued with, so that requests
+	// without a duration are evaluated against their issuer's default.
+	duration, err := p.effectiveDuration(ctx, cr)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if err := p.evaluateConstraints(ctx, el, path, &policy.Spec.PolicyConstraints, cr, csr, duration); err != nil {
+		return err
+	}
+
+	return p.evaluateRules(ctx, el, path.Child("rules"), policy.Spec.Rules, cr, csr, duration)
+}
+
+// evaluateConstraints evaluates whether the given CertificateRequest satisfies
+// the constraints, populating 'el' with each constraint it violates.
+func (p *Policy) evaluateConstraints(ctx context.Context, el *field.ErrorList, path *field.Path, constraints *cmpolicy.PolicyConstraints, cr *cmapi.CertificateRequest, csr *x509.CertificateRequest, duration *metav1.Duration) error {
+	if err := evaluatex509Subject(el, path.Child("allowedSubject"), constraints.AllowedSubject, constraints.AllowedCommonName, csr); err != nil {
+		return err
+	}
+	if err := evaluatePrivateKey(el, path.Child("allowedPrivateKey"), constraints.AllowedPrivateKey, csr); err != nil {
+		return err
+	}
+
+	checks.String(el, path.Child("allowedCommonName"), constraints.AllowedCommonName, csr.Subject.CommonName)
+	checks.StringSlice(el, path.Child("allowedDNSNames"), constraints.AllowedDNSNames, csr.DNSNames)
+	checks.IPSlice(el, path.Child("allowedIPAddresses"), constraints.AllowedIPAddresses, csr.IPAddresses)
+	checks.URLSlice(el, path.Child("allowedURIs"), constraints.AllowedURIs, csr.URIs)
+	checks.StringSlice(el, path.Child("allowedEmailAddresses"), constraints.AllowedEmailAddresses, csr.EmailAddresses)
+	checks.KeyUsageSlice(el, path.Child("allowedUsages"), constraints.AllowedUsages, cr.Spec.Usages)
+
+	// A request is for a CA if either the CertificateRequest or the basic
+	// constraints extension of the CSR says so.
+	isCA, err := requestIsCA(cr, csr)
+	if err != nil {
+		return err
+	}
+	checks.Bool(el, path.Child("allowedIsCA"), constraints.AllowedIsCA, isCA)
+
+	checks.CommonNameFormat(el, path.Child("commonNameFormat"), constraints.CommonNameFormat, csr.Subject.CommonName)
+	checks.CommonNameInSANs(el, path.Child("commonNameMustBeSAN"), constraints.CommonNameMustBeSAN, csr)
+
+	evaluateDNSNames(el, path.Child("dnsNames"), constraints.DNSNames, csr.DNSNames)
+	if err := p.evaluateDNSNameOwnership(ctx, el, path.Child("dnsNames"), constraints.DNSNames, csr.DNSNames, cr.Namespace); err != nil {
+		return err
+	}
+	if err := p.evaluateIssuer(ctx, el, path.Child("allowedIssuer"), constraints.AllowedIssuers, cr); err != nil {
+		return err
+	}
+	evaluateRequiredKeyValues(el, path.Child("requiredAnnotations"), constraints.RequiredAnnotations, cr.Annotations)
+	evaluateRequiredKeyValues(el, path.Child("requiredLabels"), constraints.RequiredLabels, cr.Labels)
+	evaluateAllowedKeyValues(el, path.Child("allowedAnnotations"), constraints.AllowedAnnotations, cr.Annotations)
+	if err := p.evaluateOwningCertificate(ctx, el, path.Child("requireOwningCertificate"), constraints.RequireOwningCertificate, cr, csr); err != nil {
+		return err
+	}
+	if err := p.evaluateCertificateSecret(ctx, el, path.Child("certificateSecret"), constraints.CertificateSecret, cr); err != nil {
+		return err
+	}
+	if err := p.evaluatePodIdentity(ctx, el, path.Child("podIdentity"), constraints.PodIdentity, cr, csr.IPAddresses, csr.DNSNames); err != nil {
+		return err
+	}
+	if err := p.evaluateNodeIdentity(ctx, el, path.Child("nodeIdentity"), constraints.NodeIdentity, cr, csr); err != nil {
+		return err
+	}
+	evaluateSPIFFE(el, path.Child("spiffe"), constraints.SPIFFE, cr, csr)
+	checks.IPAddressCategories(el, path.Child("ipAddresses"), constraints.IPAddresses, csr.IPAddresses)
+	evaluateWildcardCertificates(el, path.Child("wildcardCertificates"), constraints.WildcardCertificates, csr.DNSNames, duration)
+
+	if constraints.RequireExplicitDuration != nil && *constraints.RequireExplicitDuration && cr.Spec.Duration == nil {
+		*el = append(*el, field.Required(path.Child("requireExplicitDuration"), "request must specify a duration"))
+	}
+	if err := p.evaluateIssuerNameConstraints(ctx, el, path.Child("enforceIssuerNameConstraints"), constraints.EnforceIssuerNameConstraints, cr, csr); err != nil {
+		return err
+	}
+	if err := p.evaluateIssuerCAExpiry(ctx, el, path.Child("issuerCAExpiry"), constraints.IssuerCAExpiry, cr, duration); err != nil {
+		return err
+	}
+	checks.MinDuration(el, path.Child("minDuration"), constraints.MinDuration, duration)
+	checks.MaxDuration(el, path.Child("maxDuration"), constraints.MaxDuration, duration)
+
+	// Denied values are checked after the allowed values, so that they carve
+	// exceptions out of the allowed patterns.
+	if len(csr.Subject.CommonName) > 0 {
+		checks.DeniedDNSNames(el, path.Child("deniedCommonNames"), constraints.DeniedCommonNames, []string{csr.Subject.CommonName})
+	}
+	checks.DeniedDNSNames(el, path.Child("deniedDNSNames"), constraints.DeniedDNSNames, csr.DNSNames)
+	checks.Denied(el, path.Child("deniedIPAddresses"), constraints.DeniedIPAddresses, ipStrings(csr.IPAddresses))
+	checks.Denied(el, path.Child("deniedURIs"), constraints.DeniedURIs, uriStrings(csr.URIs))
+	checks.Denied(el, path.Child("deniedEmailAddresses"), constraints.DeniedEmailAddresses, csr.EmailAddresses)
+
+	return evaluateDeniedSubject(el, path.Child("allowedSubject"), constraints.AllowedSubject, csr)
 }
 
-func evaluatex509Subject(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyX509Subject, subject pkix.Name) []check {
+// evaluateLimits will add errors for each limit that the request exceeds.
+// Returns false if any limit was exceeded, in which case the request should not
+// be evaluated any further.
+func evaluateLimits(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyLimits, csr *x509.CertificateRequest) bool {
+	n := len(*el)
+	totalSANs := len(csr.DNSNames) + len(csr.IPAddresses) + len(csr.URIs) + len(csr.EmailAddresses)
+	checks.MaxSize(el, path.Child("maxDNSNames"), policy.MaxDNSNames, len(csr.DNSNames))
+	checks.MaxSize(el, path.Child("maxIPAddresses"), policy.MaxIPAddresses, len(csr.IPAddresses))
+	checks.MaxSize(el, path.Child("maxURIs"), policy.MaxURIs, len(csr.URIs))
+	checks.MaxSize(el, path.Child("maxEmailAddresses"), policy.MaxEmailAddresses, len(csr.EmailAddresses))
+	checks.MaxSize(el, path.Child("maxTotalSANs"), intOrDefault(policy.MaxTotalSANs, defaultMaxTotalSANs), totalSANs)
+
+	// Don't inspect the length of every name if there are too many of them.
+	if len(*el) > n {
+		return false
+	}
+
+	checks.MaxLabelLength(el, path.Child("maxDNSLabelLength"), intOrDefault(policy.MaxDNSLabelLength, defaultMaxDNSLabelLength), csr.DNSNames)
+	checks.MaxLength(el, path.Child("maxDNSNameLength"), intOrDefault(policy.MaxDNSNameLength, defaultMaxDNSNameLength), csr.DNSNames)
+	checks.MaxLength(el, path.Child("maxCommonNameLength"), intOrDefault(policy.MaxCommonNameLength, defaultMaxCommonNameLength), []string{csr.Subject.CommonName})
+
+	return len(*el) == n
+}
+
+func evaluatex509Subject(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyX509Subject, allowedCommonName *string, csr *x509.CertificateRequest) error {
 	// Allow all
 	if policy == nil {
 		return nil
 	}
 
-	return []check{
+	// Decode the raw subject, since csr.Subject does not expose every
+	// attribute type.
+	rdns, err := decodeSubject(csr.RawSubject)
+	if err != nil {
+		return err
+	}
+
+	evaluateSubjectAttributes(el, path, policy, allowedCommonName, rdns)
+	checks.DistinguishedNames(el, path.Child("allowedDistinguishedNames"), policy.AllowedDistinguishedNames, distinguishedName(rdns))
+
+	subject := csr.Subject
+	values := subjectValues(rdns)
+	for _, allowed := range []struct {
+		name    string
+		policy  *[]string
+		request []string
+	}{
 		{"allowedOrganizations", policy.AllowedOrganizations, subject.Organization},
 		{"allowedCountries", policy.AllowedCountries, subject.Country},
 		{"allowedOrganizationalUnits", policy.AllowedOrganizationalUnits, subject.OrganizationalUnit},
@@ -132,31 +253,100 @@ func evaluatex509Subject(el *field.ErrorList, path *field.Path, policy *cmpolicy
 		{"allowedProvinces", policy.AllowedProvinces, subject.Province},
 		{"allowedStreetAddresses", policy.AllowedStreetAddresses, subject.StreetAddress},
 		{"allowedPostalCodes", policy.AllowedPostalCodes, subject.PostalCode},
-		{"allowedSerialNumber", policy.AllowedSerialNumber, subject.SerialNumber},
+		{"allowedDomainComponents", policy.AllowedDomainComponents, values[oidDomainComponent.String()]},
+		{"allowedUIDs", policy.AllowedUIDs, values[oidUID.String()]},
+		{"allowedTitles", policy.AllowedTitles, values[oidTitle.String()]},
+		{"allowedEmailAddresses", policy.AllowedEmailAddresses, values[oidEmailAddress.String()]},
+	} {
+		checks.StringSlice(el, path.Child(allowed.name), allowed.policy, allowed.request)
 	}
+	checks.String(el, path.Child("allowedSerialNumber"), policy.AllowedSerialNumber, subject.SerialNumber)
+
+	return nil
 }
 
-func evaluatePrivateKey(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyPrivateKey, csr *x509.CertificateRequest) ([]check, error) {
+// evaluateDeniedSubject will add an error for each subject attribute value
+// which matches any of its denied patterns.
+func evaluateDeniedSubject(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyX509Subject, csr *x509.CertificateRequest) error {
+	// Deny none
+	if policy == nil {
+		return nil
+	}
+
+	rdns, err := decodeSubject(csr.RawSubject)
+	if err != nil {
+		return err
+	}
+
+	subject := csr.Subject
+	values := subjectValues(rdns)
+	for _, denied := range []struct {
+		name    string
+		policy  *[]string
+		request []string
+	}{
+		{"deniedOrganizations", policy.DeniedOrganizations, subject.Organization},
+		{"deniedCountries", policy.DeniedCountries, subject.Country},
+		{"deniedOrganizationalUnits", policy.DeniedOrganizationalUnits, subject.OrganizationalUnit},
+		{"deniedLocalities", policy.DeniedLocalities, subject.Locality},
+		{"deniedProvinces", policy.DeniedProvinces, subject.Province},
+		{"deniedStreetAddresses", policy.DeniedStreetAddresses, subject.StreetAddress},
+		{"deniedPostalCodes", policy.DeniedPostalCodes, subject.PostalCode},
+		{"deniedSerialNumbers", policy.DeniedSerialNumbers, values[oidSerialNumber.String()]},
+		{"deniedDomainComponents", policy.DeniedDomainComponents, values[oidDomainComponent.String()]},
+		{"deniedUIDs", policy.DeniedUIDs, values[oidUID.String()]},
+		{"deniedTitles", policy.DeniedTitles, values[oidTitle.String()]},
+		{"deniedEmailAddresses", policy.DeniedEmailAddresses, values[oidEmailAddress.String()]},
+	} {
+		checks.Denied(el, path.Child(denied.name), denied.policy, denied.request)
+	}
+
+	return nil
+}
+
+func evaluatePrivateKey(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyPrivateKey, csr *x509.CertificateRequest) error {
 	// Allow all
 	if policy == nil {
-		return nil, nil
+		return nil
 	}
 
 	alg, size, err := parsePublicKey(csr.PublicKey)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if policy.AllowedAlgorithm != nil && alg != *policy.AllowedAlgorithm {
+		*el = append(*el, field.Invalid(path.Child("allowedAlgorithm"), alg, string(*policy.AllowedAlgorithm)))
+	}
+	checks.MinSize(el, path.Child("allowedMinSize"), policy.MinSize, size)
+	checks.MaxSize(el, path.Child("allowedMaxSize"), policy.MaxSize, size)
+
+	return nil
+}
+
+// requestIsCA returns true if the CertificateRequest is for a CA, or the CSR
+// has a basic constraints extension marking it as a CA.
+func requestIsCA(cr *cmapi.CertificateRequest, csr *x509.CertificateRequest) (bool, error) {
+	if cr.Spec.IsCA {
+		return true, nil
 	}
 
-	checks.MinSize(el, path.Child("minSize"), policy.MinSize, size)
-	checks.MaxSize(el, path.Child("minSize"), policy.MaxSize, size)
+	for _, ext := range csr.Extensions {
+		if !ext.Id.Equal(oidExtensionBasicConstraints) {
+			continue
+		}
+		var constraints basicConstraints
+		if rest, err := asn1.Unmarshal(ext.Value, &constraints); err != nil {
+			return false, err
+		} else if len(rest) > 0 {
+			return false, errors.New("trailing data after basic constraints extension")
+		}
+		return constraints.IsCA, nil
+	}
 
-	return []check{
-		{"allowedAlgorithm", policy.AllowedAlgorithm, alg},
-	}, nil
+	return false, nil
 }
 
-// parsePublicKey will return the algorithm and size of the given public key.
-// If the public key cannot be decoded, returns error.
 func parsePublicKey(pub interface{}) (cmapi.PrivateKeyAlgorithm, int, error) {
 	switch pub.(type) {
 	case *rsa.PublicKey:
@@ -164,7 +354,7 @@ func parsePublicKey(pub interface{}) (cmapi.PrivateKeyAlgorithm, int, error) {
 		if !ok {
 			return "", -1, parseKeyError
 		}
-		return cmapi.RSAKeyAlgorithm, rsapub.Size(), nil
+		return cmapi.RSAKeyAlgorithm, rsapub.N.BitLen(), nil
 	case *ecdsa.PublicKey:
 		ecdsapub, ok := pub.(*ecdsa.PublicKey)
 		if !ok {
@@ -175,3 +365,11 @@ func parsePublicKey(pub interface{}) (cmapi.PrivateKeyAlgorithm, int, error) {
 		return "", -1, parseKeyError
 	}
 }
+
+// intOrDefault returns i if it is not nil, else a pointer to def.
+func intOrDefault(i *int, def int) *int {
+	if i != nil {
+		return i
+	}
+	return &def
+}
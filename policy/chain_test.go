@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// mustCSR returns the PEM encoded CSR of the template, signed with a new
+// P-256 key.
+func mustCSR(t *testing.T, template *x509.CertificateRequest) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mustCSRWithKey(t, template, key)
+}
+
+// mustCSRWithKey returns the PEM encoded CSR of the template, signed with the
+// key.
+func mustCSRWithKey(t *testing.T, template *x509.CertificateRequest, key crypto.Signer) []byte {
+	t.Helper()
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+// mustParseCSR returns the parsed CSR of the template.
+func mustParseCSR(t *testing.T, template *x509.CertificateRequest) *x509.CertificateRequest {
+	t.Helper()
+
+	block, _ := pem.Decode(mustCSR(t, template))
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestEvaluateLimits(t *testing.T) {
+	dnsNames := func(n int) []string {
+		var names []string
+		for i := 0; i < n; i++ {
+			names = append(names, fmt.Sprintf("%d.example.com", i))
+		}
+		return names
+	}
+
+	tests := map[string]struct {
+		policy  *cmpolicy.PolicyLimits
+		csr     *x509.CertificateRequest
+		expErrs int
+	}{
+		"no limits, default max total SANs": {
+			policy:  new(cmpolicy.PolicyLimits),
+			csr:     &x509.CertificateRequest{DNSNames: dnsNames(defaultMaxTotalSANs)},
+			expErrs: 0,
+		},
+		"no limits, above default max total SANs": {
+			policy:  new(cmpolicy.PolicyLimits),
+			csr:     &x509.CertificateRequest{DNSNames: dnsNames(defaultMaxTotalSANs + 1)},
+			expErrs: 1,
+		},
+		"DNS names at max": {
+			policy:  &cmpolicy.PolicyLimits{MaxDNSNames: intPtr(2)},
+			csr:     &x509.CertificateRequest{DNSNames: dnsNames(2)},
+			expErrs: 0,
+		},
+		"DNS names above max": {
+			policy:  &cmpolicy.PolicyLimits{MaxDNSNames: intPtr(2)},
+			csr:     &x509.CertificateRequest{DNSNames: dnsNames(3)},
+			expErrs: 1,
+		},
+		"default DNS label length": {
+			policy:  new(cmpolicy.PolicyLimits),
+			csr:     &x509.CertificateRequest{DNSNames: []string{strings.Repeat("a", 63) + ".com"}},
+			expErrs: 0,
+		},
+		"above default DNS label length": {
+			policy:  new(cmpolicy.PolicyLimits),
+			csr:     &x509.CertificateRequest{DNSNames: []string{strings.Repeat("a", 64) + ".com"}},
+			expErrs: 1,
+		},
+		"common name at max length": {
+			policy: &cmpolicy.PolicyLimits{MaxCommonNameLength: intPtr(5)},
+			csr: func() *x509.CertificateRequest {
+				csr := new(x509.CertificateRequest)
+				csr.Subject.CommonName = "abcde"
+				return csr
+			}(),
+			expErrs: 0,
+		},
+		"common name above max length": {
+			policy: &cmpolicy.PolicyLimits{MaxCommonNameLength: intPtr(5)},
+			csr: func() *x509.CertificateRequest {
+				csr := new(x509.CertificateRequest)
+				csr.Subject.CommonName = "abcdef"
+				return csr
+			}(),
+			expErrs: 1,
+		},
+		"multibyte common name within default max length": {
+			policy: new(cmpolicy.PolicyLimits),
+			csr: func() *x509.CertificateRequest {
+				csr := new(x509.CertificateRequest)
+				csr.Subject.CommonName = strings.Repeat("証明書", 10)
+				return csr
+			}(),
+			expErrs: 0,
+		},
+		"multibyte common name above default max length": {
+			policy: new(cmpolicy.PolicyLimits),
+			csr: func() *x509.CertificateRequest {
+				csr := new(x509.CertificateRequest)
+				csr.Subject.CommonName = strings.Repeat("証", defaultMaxCommonNameLength+1)
+				return csr
+			}(),
+			expErrs: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			ok := evaluateLimits(&el, field.NewPath("spec", "limits"), test.policy, test.csr)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+			if ok != (test.expErrs == 0) {
+				t.Errorf("unexpected result: got=%t", ok)
+			}
+		})
+	}
+}
+
+func TestEvaluateCertificateRequestMaxRequestSize(t *testing.T) {
+	tests := map[string]struct {
+		limits  *cmpolicy.PolicyLimits
+		size    int
+		expErrs int
+	}{
+		"no limits, at default max request size": {
+			limits: nil, size: defaultMaxRequestSize, expErrs: 0,
+		},
+		"no limits, above default max request size": {
+			limits: nil, size: defaultMaxRequestSize + 1, expErrs: 1,
+		},
+		"above max request size": {
+			limits: &cmpolicy.PolicyLimits{MaxRequestSize: intPtr(10)}, size: 11, expErrs: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := &cmpolicy.CertificateRequestPolicy{
+				Spec: cmpolicy.CertificateRequestPolicySpec{Limits: test.limits},
+			}
+			cr := &cmapi.CertificateRequest{
+				Spec: cmapi.CertificateRequestSpec{Request: make([]byte, test.size)},
+			}
+
+			var el field.ErrorList
+			// Requests within the size limit are not valid CSRs, and so
+			// fail to decode.
+			err := New(nil, Options{}).EvaluateCertificateRequest(context.TODO(), &el, policy, cr)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+			if (err == nil) != (test.expErrs > 0) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEvaluateConstraintsIsCAAndAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caExtension, err := asn1.Marshal(basicConstraints{IsCA: true, MaxPathLen: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isCA, notCA := true, false
+	ecdsaAlgorithm, rsaAlgorithm := cmapi.ECDSAKeyAlgorithm, cmapi.RSAKeyAlgorithm
+
+	tests := map[string]struct {
+		constraints cmpolicy.PolicyConstraints
+		rules       []cmpolicy.PolicyRule
+		isCA        bool
+		csr         []byte
+		expErrs     int
+	}{
+		"not a CA": {
+			constraints: cmpolicy.PolicyConstraints{AllowedIsCA: &notCA},
+			csr:         mustCSR(t, &x509.CertificateRequest{}),
+			expErrs:     0,
+		},
+		"CA CertificateRequest": {
+			constraints: cmpolicy.PolicyConstraints{AllowedIsCA: &notCA},
+			isCA:        true,
+			csr:         mustCSR(t, &x509.CertificateRequest{}),
+			expErrs:     1,
+		},
+		"CA basic constraints in the CSR": {
+			constraints: cmpolicy.PolicyConstraints{AllowedIsCA: &notCA},
+			csr: mustCSR(t, &x509.CertificateRequest{
+				ExtraExtensions: []pkix.Extension{{Id: oidExtensionBasicConstraints, Critical: true, Value: caExtension}},
+			}),
+			expErrs: 1,
+		},
+		"ECDSA key": {
+			constraints: cmpolicy.PolicyConstraints{AllowedPrivateKey: &cmpolicy.PolicyPrivateKey{AllowedAlgorithm: &ecdsaAlgorithm}},
+			csr:         mustCSR(t, &x509.CertificateRequest{}),
+			expErrs:     0,
+		},
+		"RSA key": {
+			constraints: cmpolicy.PolicyConstraints{AllowedPrivateKey: &cmpolicy.PolicyPrivateKey{AllowedAlgorithm: &ecdsaAlgorithm}},
+			csr:         mustCSRWithKey(t, &x509.CertificateRequest{}, rsaKey),
+			expErrs:     1,
+		},
+		"rule for RSA keys denying CAs": {
+			rules: []cmpolicy.PolicyRule{{
+				Match:             cmpolicy.PolicyRuleMatch{KeyAlgorithm: &rsaAlgorithm},
+				PolicyConstraints: cmpolicy.PolicyConstraints{AllowedIsCA: &notCA},
+			}},
+			isCA:    true,
+			csr:     mustCSRWithKey(t, &x509.CertificateRequest{}, rsaKey),
+			expErrs: 1,
+		},
+		"rule for CAs requiring ECDSA keys": {
+			rules: []cmpolicy.PolicyRule{{
+				Match:             cmpolicy.PolicyRuleMatch{IsCA: &isCA},
+				PolicyConstraints: cmpolicy.PolicyConstraints{AllowedPrivateKey: &cmpolicy.PolicyPrivateKey{AllowedAlgorithm: &ecdsaAlgorithm}},
+			}},
+			isCA:    true,
+			csr:     mustCSRWithKey(t, &x509.CertificateRequest{}, rsaKey),
+			expErrs: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{Spec: cmapi.CertificateRequestSpec{IsCA: test.isCA, Request: test.csr}}
+			block, _ := pem.Decode(test.csr)
+			csr, err := x509.ParseCertificateRequest(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var el field.ErrorList
+			p, path, duration := New(nil, Options{}), field.NewPath("spec"), &metav1.Duration{Duration: time.Hour}
+			if err := p.evaluateConstraints(context.TODO(), &el, path, &test.constraints, cr, csr, duration); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.evaluateRules(context.TODO(), &el, path.Child("rules"), test.rules, cr, csr, duration); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
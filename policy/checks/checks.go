@@ -17,15 +17,23 @@ limitations under the License.
 package checks
 
 import (
+	"crypto/x509"
 	"fmt"
 	"net"
+	"net/mail"
+	"net/netip"
 	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
 	"github.com/cert-manager/policy-approver/policy/checks/wildcard"
 )
 
@@ -42,6 +50,18 @@ func String(el *field.ErrorList, path *field.Path, policy *string, request strin
 	}
 }
 
+// Bool will match a policy bool against a given bool value.
+func Bool(el *field.ErrorList, path *field.Path, policy *bool, request bool) {
+	// Allow all
+	if policy == nil {
+		return
+	}
+
+	if *policy != request {
+		*el = append(*el, field.Invalid(path, request, fmt.Sprintf("%t", *policy)))
+	}
+}
+
 // Strings will match a policy string slice against a given string value, using
 // wildcard contains.
 func Strings(el *field.ErrorList, path *field.Path, policy *[]string, request string) {
@@ -88,6 +108,209 @@ func URLSlice(el *field.ErrorList, path *field.Path, policy *[]string, request [
 	StringSlice(el, path, policy, urls)
 }
 
+// Denied will add an error for each request value which matches any of the
+// denied patterns, using wildcard match.
+func Denied(el *field.ErrorList, path *field.Path, policy *[]string, request []string) {
+	// Deny none
+	if policy == nil {
+		return
+	}
+
+	for _, value := range request {
+		for _, pattern := range *policy {
+			if wildcard.Matchs(pattern, value) {
+				*el = append(*el, field.Invalid(path, value, fmt.Sprintf("denied by %q", pattern)))
+				break
+			}
+		}
+	}
+}
+
+// DeniedDNSNames will add an error for each requested DNS name which matches
+// any of the denied patterns. DNS names and patterns are compared case
+// insensitively. A wildcard DNS name is denied if any name it covers matches
+// a denied pattern, since a certificate for "*.example.com" is also valid
+// for "login.example.com".
+func DeniedDNSNames(el *field.ErrorList, path *field.Path, policy *[]string, request []string) {
+	// Deny none
+	if policy == nil {
+		return
+	}
+
+	for _, dnsName := range request {
+		name := strings.ToLower(strings.TrimSuffix(dnsName, "."))
+		for _, pattern := range *policy {
+			pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+			if wildcard.Matchs(pattern, name) || (strings.HasPrefix(name, "*.") && matchesCoveredName(pattern, name[2:])) {
+				*el = append(*el, field.Invalid(path, dnsName, fmt.Sprintf("denied by %q", pattern)))
+				break
+			}
+		}
+	}
+}
+
+// matchesCoveredName returns true if the pattern matches any name covered by
+// a wildcard of the domain, i.e. any single label followed by the domain. The
+// pattern is matched against the names as an automaton: state 0 is before the
+// label, state 1 within the label, and state 2+k having matched k runes of
+// "."+domain.
+func matchesCoveredName(pattern, domain string) bool {
+	p, tail := []rune(pattern), []rune("."+domain)
+	final := 2 + len(tail)
+
+	// next returns the states reachable from t by consuming r.
+	next := func(t int, r rune) []int {
+		switch {
+		case t < 2 && r != '.':
+			return []int{1}
+		case t == 1 && r == tail[0]:
+			return []int{3}
+		case t >= 2 && t < final && r == tail[t-2]:
+			return []int{t + 1}
+		}
+		return nil
+	}
+	// anyRune returns the states reachable from t by consuming any rune.
+	anyRune := func(t int) []int {
+		switch {
+		case t == 0:
+			return []int{1}
+		case t == 1:
+			return []int{1, 3}
+		case t < final:
+			return []int{t + 1}
+		}
+		return nil
+	}
+
+	type state struct{ p, t int }
+	seen := make(map[state]bool)
+	stack := []state{{0, 0}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+
+		if s.p == len(p) {
+			if s.t == final {
+				return true
+			}
+			continue
+		}
+
+		if p[s.p] == '*' {
+			stack = append(stack, state{s.p + 1, s.t})
+			for _, t := range anyRune(s.t) {
+				stack = append(stack, state{s.p, t})
+			}
+			continue
+		}
+		for _, t := range next(s.t, p[s.p]) {
+			stack = append(stack, state{s.p + 1, t})
+		}
+	}
+
+	return false
+}
+
+// cloudMetadataAddresses are the instance metadata endpoints of cloud
+// providers.
+var cloudMetadataAddresses = []netip.Addr{
+	// AWS, Azure, GCP, OpenStack and others.
+	netip.MustParseAddr("169.254.169.254"),
+	// AWS IPv6.
+	netip.MustParseAddr("fd00:ec2::254"),
+	// Alibaba Cloud.
+	netip.MustParseAddr("100.100.100.200"),
+}
+
+// sharedAddressSpace is the RFC 6598 shared address space, used by carrier
+// grade NAT.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IPAddressCategories will check that every category of each request IP
+// address is allowed, and not denied, by the policy.
+func IPAddressCategories(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyIPAddresses, request []net.IP) {
+	// Allow all
+	if policy == nil {
+		return
+	}
+
+	for _, ip := range request {
+		categories := ClassifyIPAddress(ip)
+
+		if policy.AllowedCategories != nil {
+			for _, category := range categories {
+				if !containsCategory(*policy.AllowedCategories, category) {
+					*el = append(*el, field.Invalid(path.Child("allowedCategories"), ip.String(),
+						fmt.Sprintf("IP address category %s is not in %v", category, *policy.AllowedCategories)))
+				}
+			}
+		}
+
+		if policy.DeniedCategories != nil {
+			for _, category := range categories {
+				if containsCategory(*policy.DeniedCategories, category) {
+					*el = append(*el, field.Invalid(path.Child("deniedCategories"), ip.String(),
+						fmt.Sprintf("IP address category %s is denied", category)))
+				}
+			}
+		}
+	}
+}
+
+// ClassifyIPAddress returns the categories of the given IP address. Cloud
+// metadata addresses are also categorised by their range. IPv4-mapped IPv6
+// addresses are categorised as their IPv4 address.
+func ClassifyIPAddress(ip net.IP) []cmpolicy.IPAddressCategory {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return []cmpolicy.IPAddressCategory{cmpolicy.IPAddressCategoryOther}
+	}
+	addr = addr.Unmap()
+
+	var categories []cmpolicy.IPAddressCategory
+	for _, metadata := range cloudMetadataAddresses {
+		if addr == metadata {
+			categories = append(categories, cmpolicy.IPAddressCategoryCloudMetadata)
+			break
+		}
+	}
+
+	switch {
+	case addr.IsUnspecified():
+		categories = append(categories, cmpolicy.IPAddressCategoryUnspecified)
+	case addr.IsLoopback():
+		categories = append(categories, cmpolicy.IPAddressCategoryLoopback)
+	case addr.IsLinkLocalUnicast():
+		categories = append(categories, cmpolicy.IPAddressCategoryLinkLocal)
+	case addr.IsMulticast():
+		categories = append(categories, cmpolicy.IPAddressCategoryMulticast)
+	case addr.IsPrivate():
+		categories = append(categories, cmpolicy.IPAddressCategoryPrivate)
+	case sharedAddressSpace.Contains(addr):
+		categories = append(categories, cmpolicy.IPAddressCategoryShared)
+	case addr.IsGlobalUnicast():
+		categories = append(categories, cmpolicy.IPAddressCategoryPublic)
+	default:
+		categories = append(categories, cmpolicy.IPAddressCategoryOther)
+	}
+
+	return categories
+}
+
+func containsCategory(categories []cmpolicy.IPAddressCategory, category cmpolicy.IPAddressCategory) bool {
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
 // KeyUsageSlice will match a policy key usage string slice against a given key
 // usage slice, using string slice on the string key usages.
 func KeyUsageSlice(el *field.ErrorList, path *field.Path, policy *[]cmapi.KeyUsage, request []cmapi.KeyUsage) {
@@ -106,37 +329,74 @@ func KeyUsageSlice(el *field.ErrorList, path *field.Path, policy *[]cmapi.KeyUsa
 	StringSlice(el, path, &policyS, requestS)
 }
 
-// ObjectReference will match a policy object reference slice against a given
-// object reference, using wildcard matches for each field. A request must
-// wildcard match a single policy slice element in its entirety.
-func ObjectReference(el *field.ErrorList, path *field.Path, policy *[]cmmeta.ObjectReference, request cmmeta.ObjectReference) {
+// DistinguishedNames will match a policy string slice of RFC 4514
+// distinguished name patterns against a given distinguished name. The request
+// must have the same number of RDNs as a single pattern, and each RDN must
+// match the RDN at the same position in that pattern. RDNs match if each
+// attribute of the pattern matches a distinct attribute of the request, in any
+// order. Attributes match if they have the same case insensitive type, and the
+// request value wildcard matches the value of the pattern.
+func DistinguishedNames(el *field.ErrorList, path *field.Path, policy *[]string, request string) {
 	// Allow all
 	if policy == nil {
 		return
 	}
 
-	var found bool
-	for _, policyI := range *policy {
-		if !wildcard.Matchs(policyI.Name, request.Name) {
+	requestRDNs := splitRDNs(request)
+	for _, pattern := range *policy {
+		patternRDNs := splitRDNs(pattern)
+		if len(patternRDNs) != len(requestRDNs) {
 			continue
 		}
-		if !wildcard.Matchs(policyI.Kind, request.Kind) {
-			continue
+
+		found := true
+		for i := range patternRDNs {
+			if !matchRDN(patternRDNs[i], requestRDNs[i]) {
+				found = false
+				break
+			}
 		}
-		if !wildcard.Matchs(policyI.Group, request.Group) {
-			continue
+		if found {
+			return
 		}
 	}
 
-	if !found {
-		*el = append(*el, field.Invalid(path, request, fmt.Sprintf("%v", *policy)))
+	*el = append(*el, field.Invalid(path, request, fmt.Sprintf("%v", *policy)))
+}
+
+// IssuerRef returns true if the issuer reference of a request matches the
+// name, kind and group of the policy issuer, using wildcard matches for each
+// field. Unset kinds and groups are defaulted as cert-manager defaults them,
+// and an unset policy name matches any name.
+func IssuerRef(policy cmpolicy.PolicyIssuer, request cmmeta.ObjectReference) bool {
+	name := policy.Name
+	if len(name) == 0 {
+		name = "*"
+	}
+	request = NormaliseIssuerRef(request)
+	normalised := NormaliseIssuerRef(cmmeta.ObjectReference{Kind: policy.Kind, Group: policy.Group})
+
+	return wildcard.Matchs(name, request.Name) &&
+		wildcard.Matchs(normalised.Kind, request.Kind) &&
+		wildcard.Matchs(normalised.Group, request.Group)
+}
+
+// NormaliseIssuerRef returns the issuer reference with an unset group
+// defaulted to cert-manager.io, and an unset kind defaulted to Issuer.
+func NormaliseIssuerRef(ref cmmeta.ObjectReference) cmmeta.ObjectReference {
+	if len(ref.Group) == 0 {
+		ref.Group = cmapi.SchemeGroupVersion.Group
+	}
+	if len(ref.Kind) == 0 {
+		ref.Kind = cmapi.IssuerKind
 	}
+	return ref
 }
 
 // MinDuration will compare the policy duration being larger than the request.
 func MinDuration(el *field.ErrorList, path *field.Path, policy *metav1.Duration, request *metav1.Duration) {
 	// Allow all
-	if policy == nil {
+	if policy == nil || request == nil {
 		return
 	}
 
@@ -148,7 +408,7 @@ func MinDuration(el *field.ErrorList, path *field.Path, policy *metav1.Duration,
 // MaxDuration will compare the request duration being larger than the policy.
 func MaxDuration(el *field.ErrorList, path *field.Path, policy *metav1.Duration, request *metav1.Duration) {
 	// Allow all
-	if policy == nil {
+	if policy == nil || request == nil {
 		return
 	}
 
@@ -180,3 +440,324 @@ func MaxSize(el *field.ErrorList, path *field.Path, policy *int, request int) {
 		*el = append(*el, field.Invalid(path, request, fmt.Sprintf("%d", *policy)))
 	}
 }
+
+// MaxLength will compare the length of each request string being larger than
+// the policy. Length is counted in characters rather than bytes, matching the
+// upper bounds defined by RFC 5280.
+func MaxLength(el *field.ErrorList, path *field.Path, policy *int, request []string) {
+	// Allow all
+	if policy == nil {
+		return
+	}
+
+	for _, r := range request {
+		if utf8.RuneCountInString(r) > *policy {
+			*el = append(*el, field.Invalid(path, r, fmt.Sprintf("%d", *policy)))
+		}
+	}
+}
+
+// MaxLabelLength will compare the length of each dot separated label of each
+// request string being larger than the policy. Length is counted in
+// characters rather than bytes.
+func MaxLabelLength(el *field.ErrorList, path *field.Path, policy *int, request []string) {
+	// Allow all
+	if policy == nil {
+		return
+	}
+
+	for _, r := range request {
+		for _, label := range strings.Split(r, ".") {
+			if utf8.RuneCountInString(label) > *policy {
+				*el = append(*el, field.Invalid(path, r, fmt.Sprintf("%d", *policy)))
+				break
+			}
+		}
+	}
+}
+
+// CommonNameFormat will check that a non-empty common name conforms to the
+// policy syntax class.
+func CommonNameFormat(el *field.ErrorList, path *field.Path, policy *cmpolicy.CommonNameFormat, request string) {
+	// Allow all
+	if policy == nil || len(request) == 0 {
+		return
+	}
+
+	var ok bool
+	switch *policy {
+	case cmpolicy.CommonNameFormatHostname:
+		ok = isHostname(request)
+	case cmpolicy.CommonNameFormatEmail:
+		ok = isEmailAddress(request)
+	case cmpolicy.CommonNameFormatFreeText:
+		ok = isFreeText(request)
+	}
+
+	if !ok {
+		*el = append(*el, field.Invalid(path, request, fmt.Sprintf("must be of format %s", *policy)))
+	}
+}
+
+// CommonNameInSANs will check that a non-empty common name is also requested
+// as a DNS name, IP address or email address.
+func CommonNameInSANs(el *field.ErrorList, path *field.Path, policy *bool, request *x509.CertificateRequest) {
+	cn := request.Subject.CommonName
+
+	// Allow all
+	if policy == nil || !*policy || len(cn) == 0 {
+		return
+	}
+
+	for _, dnsName := range request.DNSNames {
+		if strings.EqualFold(cn, dnsName) {
+			return
+		}
+	}
+	if ip := net.ParseIP(cn); ip != nil {
+		for _, requestIP := range request.IPAddresses {
+			if ip.Equal(requestIP) {
+				return
+			}
+		}
+	}
+	for _, email := range request.EmailAddresses {
+		if strings.EqualFold(cn, email) {
+			return
+		}
+	}
+
+	*el = append(*el, field.Invalid(path, cn, "common name must also be requested as a DNS name, IP address or email address"))
+}
+
+// NameConstraints will check each DNS name, IP address, email address and URI
+// of the request against the X.509 name constraints of the CA certificate,
+// as they are evaluated by the Go verifier. A name must be within a permitted
+// subtree of its type, if any are present, and within none of the excluded
+// subtrees.
+func NameConstraints(el *field.ErrorList, path *field.Path, ca *x509.Certificate, request *x509.CertificateRequest) {
+	for _, dnsName := range request.DNSNames {
+		if !nameConstraintsAllow(dnsName, ca.PermittedDNSDomains, ca.ExcludedDNSDomains, matchDomainConstraint) {
+			*el = append(*el, field.Invalid(path.Child("dnsNames"), dnsName, "not allowed by the name constraints of the issuer CA"))
+		}
+	}
+
+	for _, ip := range request.IPAddresses {
+		if !ipConstraintsAllow(ip, ca.PermittedIPRanges, ca.ExcludedIPRanges) {
+			*el = append(*el, field.Invalid(path.Child("ipAddresses"), ip.String(), "not allowed by the name constraints of the issuer CA"))
+		}
+	}
+
+	for _, email := range request.EmailAddresses {
+		if !nameConstraintsAllow(email, ca.PermittedEmailAddresses, ca.ExcludedEmailAddresses, matchEmailConstraint) {
+			*el = append(*el, field.Invalid(path.Child("emailAddresses"), email, "not allowed by the name constraints of the issuer CA"))
+		}
+	}
+
+	for _, uri := range request.URIs {
+		if !nameConstraintsAllow(uri.Hostname(), ca.PermittedURIDomains, ca.ExcludedURIDomains, matchDomainConstraint) {
+			*el = append(*el, field.Invalid(path.Child("uris"), uri.String(), "not allowed by the name constraints of the issuer CA"))
+		}
+	}
+}
+
+// nameConstraintsAllow returns true if the name matches any of the permitted
+// constraints, or there are none, and matches none of the excluded
+// constraints.
+func nameConstraintsAllow(name string, permitted, excluded []string, match func(name, constraint string) bool) bool {
+	for _, constraint := range excluded {
+		if match(name, constraint) {
+			return false
+		}
+	}
+
+	if len(permitted) == 0 {
+		return true
+	}
+	for _, constraint := range permitted {
+		if match(name, constraint) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipConstraintsAllow returns true if the IP address is within any of the
+// permitted ranges, or there are none, and within none of the excluded ranges.
+// Ranges of a different address family never match.
+func ipConstraintsAllow(ip net.IP, permitted, excluded []*net.IPNet) bool {
+	match := func(ipNet *net.IPNet) bool {
+		if (ip.To4() != nil) != (len(ipNet.Mask) == net.IPv4len) {
+			return false
+		}
+		return ipNet.Contains(ip)
+	}
+
+	for _, ipNet := range excluded {
+		if match(ipNet) {
+			return false
+		}
+	}
+
+	if len(permitted) == 0 {
+		return true
+	}
+	for _, ipNet := range permitted {
+		if match(ipNet) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDomainConstraint returns true if the domain is within the constraint.
+// A constraint matches itself and any subdomain, unless it has a leading
+// period, in which case it only matches subdomains.
+func matchDomainConstraint(domain, constraint string) bool {
+	if len(constraint) == 0 {
+		return true
+	}
+
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	constraint = strings.ToLower(constraint)
+
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(domain, constraint)
+	}
+	return domain == constraint || strings.HasSuffix(domain, "."+constraint)
+}
+
+// matchEmailConstraint returns true if the email address is within the
+// constraint. A constraint containing an "@" matches that mailbox only,
+// otherwise it is a domain constraint on the host of the address.
+func matchEmailConstraint(email, constraint string) bool {
+	if strings.Contains(constraint, "@") {
+		return strings.EqualFold(email, constraint)
+	}
+
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return false
+	}
+	host := email[i+1:]
+
+	// Without a leading period, a constraint only matches the host itself
+	if !strings.HasPrefix(constraint, ".") {
+		return strings.EqualFold(host, constraint)
+	}
+	return matchDomainConstraint(host, constraint)
+}
+
+// isHostname returns true if the given string is a valid DNS name, optionally
+// with a leading wildcard label.
+func isHostname(s string) bool {
+	s = strings.ToLower(strings.TrimPrefix(s, "*."))
+	return len(validation.IsDNS1123Subdomain(s)) == 0
+}
+
+// isEmailAddress returns true if the given string is a bare email address
+// with a valid DNS name as its domain.
+func isEmailAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	i := strings.LastIndex(s, "@")
+	return i > 0 && isHostname(s[i+1:]) && !strings.HasPrefix(s[i+1:], "*.")
+}
+
+// isFreeText returns true if the given string is valid UTF-8 and only
+// contains printable characters.
+func isFreeText(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchRDN returns true if each attribute of the pattern RDN matches a
+// distinct attribute of the request RDN. The attributes of a multi-valued RDN
+// are a set, so they may be in any order.
+func matchRDN(pattern, request string) bool {
+	patternAttrs := splitEscaped(pattern, '+')
+	requestAttrs := splitEscaped(request, '+')
+	if len(patternAttrs) != len(requestAttrs) {
+		return false
+	}
+
+	return matchAttributes(patternAttrs, requestAttrs, make([]bool, len(requestAttrs)))
+}
+
+// matchAttributes returns true if each pattern attribute can be paired with a
+// distinct request attribute which is not yet used, and which it matches.
+// Wildcard patterns may match more than one request attribute, so every
+// pairing is tried.
+func matchAttributes(patterns, request []string, used []bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for i := range request {
+		if used[i] || !matchAttribute(patterns[0], request[i]) {
+			continue
+		}
+		used[i] = true
+		if matchAttributes(patterns[1:], request, used) {
+			return true
+		}
+		used[i] = false
+	}
+
+	return false
+}
+
+// matchAttribute returns true if the request attribute has the same type as
+// the pattern attribute, ignoring case, and its value wildcard matches the
+// value of the pattern.
+func matchAttribute(pattern, request string) bool {
+	patternType, patternValue := splitAttribute(pattern)
+	requestType, requestValue := splitAttribute(request)
+	return strings.EqualFold(patternType, requestType) && wildcard.Matchs(patternValue, requestValue)
+}
+
+// splitAttribute splits an RFC 4514 attribute into its type and value.
+// Attribute types never contain an '=', so the first '=' ends the type.
+func splitAttribute(attr string) (string, string) {
+	i := strings.IndexByte(attr, '=')
+	if i < 0 {
+		return "", attr
+	}
+	return attr[:i], attr[i+1:]
+}
+
+// splitRDNs splits an RFC 4514 distinguished name on every comma which is not
+// escaped.
+func splitRDNs(dn string) []string {
+	if len(dn) == 0 {
+		return nil
+	}
+	return splitEscaped(dn, ',')
+}
+
+// splitEscaped splits the string on every separator which is not escaped.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	var start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			// Skip the escaped character.
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
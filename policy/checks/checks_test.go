@@ -0,0 +1,634 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestCommonNameFormat(t *testing.T) {
+	tests := map[string]struct {
+		format cmpolicy.CommonNameFormat
+		cn     string
+		expErr bool
+	}{
+		"empty common name: allowed": {
+			format: cmpolicy.CommonNameFormatHostname,
+			cn:     "",
+			expErr: false,
+		},
+		"hostname: allowed": {
+			format: cmpolicy.CommonNameFormatHostname,
+			cn:     "foo.example.com",
+			expErr: false,
+		},
+		"wildcard hostname: allowed": {
+			format: cmpolicy.CommonNameFormatHostname,
+			cn:     "*.example.com",
+			expErr: false,
+		},
+		"hostname with space: denied": {
+			format: cmpolicy.CommonNameFormatHostname,
+			cn:     "foo example.com",
+			expErr: true,
+		},
+		"email: allowed": {
+			format: cmpolicy.CommonNameFormatEmail,
+			cn:     "foo@example.com",
+			expErr: false,
+		},
+		"email with display name: denied": {
+			format: cmpolicy.CommonNameFormatEmail,
+			cn:     "Foo <foo@example.com>",
+			expErr: true,
+		},
+		"hostname as email: denied": {
+			format: cmpolicy.CommonNameFormatEmail,
+			cn:     "example.com",
+			expErr: true,
+		},
+		"free text: allowed": {
+			format: cmpolicy.CommonNameFormatFreeText,
+			cn:     "Acme Payments Client",
+			expErr: false,
+		},
+		"free text with control character: denied": {
+			format: cmpolicy.CommonNameFormatFreeText,
+			cn:     "Acme\x00Payments",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			CommonNameFormat(&el, field.NewPath("spec"), &test.format, test.cn)
+			if (len(el) > 0) != test.expErr {
+				t.Errorf("unexpected errors (%s, %q): exp=%t got=%v",
+					test.format, test.cn, test.expErr, el)
+			}
+		})
+	}
+}
+
+func TestCommonNameInSANs(t *testing.T) {
+	tests := map[string]struct {
+		csr    *x509.CertificateRequest
+		expErr bool
+	}{
+		"empty common name: allowed": {
+			csr:    &x509.CertificateRequest{},
+			expErr: false,
+		},
+		"common name in DNS names with different case: allowed": {
+			csr: &x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "Foo.Example.com"},
+				DNSNames: []string{"bar.example.com", "foo.example.com"},
+			},
+			expErr: false,
+		},
+		"common name in IP addresses: allowed": {
+			csr: &x509.CertificateRequest{
+				Subject:     pkix.Name{CommonName: "10.0.0.1"},
+				IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+			},
+			expErr: false,
+		},
+		"common name in email addresses: allowed": {
+			csr: &x509.CertificateRequest{
+				Subject:        pkix.Name{CommonName: "foo@example.com"},
+				EmailAddresses: []string{"foo@example.com"},
+			},
+			expErr: false,
+		},
+		"common name not a SAN: denied": {
+			csr: &x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "unapproved.example.com"},
+				DNSNames: []string{"foo.example.com"},
+			},
+			expErr: true,
+		},
+	}
+
+	policy := true
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			CommonNameInSANs(&el, field.NewPath("spec"), &policy, test.csr)
+			if (len(el) > 0) != test.expErr {
+				t.Errorf("unexpected errors: exp=%t got=%v", test.expErr, el)
+			}
+		})
+	}
+}
+
+func TestDistinguishedNames(t *testing.T) {
+	tests := map[string]struct {
+		patterns []string
+		dn       string
+		expErr   bool
+	}{
+		"exact match: allowed": {
+			patterns: []string{"CN=foo,OU=payments,O=Acme,C=GB"},
+			dn:       "CN=foo,OU=payments,O=Acme,C=GB",
+			expErr:   false,
+		},
+		"wildcard within an RDN: allowed": {
+			patterns: []string{"CN=*,OU=payments,O=Acme,C=GB"},
+			dn:       "CN=foo,OU=payments,O=Acme,C=GB",
+			expErr:   false,
+		},
+		"wildcard does not span an extra RDN: denied": {
+			patterns: []string{"CN=*,OU=payments,O=Acme,C=GB"},
+			dn:       "CN=foo,OU=other,OU=payments,O=Acme,C=GB",
+			expErr:   true,
+		},
+		"RDNs out of order: denied": {
+			patterns: []string{"CN=*,OU=payments,O=Acme,C=GB"},
+			dn:       "CN=foo,O=Acme,OU=payments,C=GB",
+			expErr:   true,
+		},
+		"escaped comma within a value: allowed": {
+			patterns: []string{"CN=*,O=Acme,C=GB"},
+			dn:       `CN=foo\,OU=payments,O=Acme,C=GB`,
+			expErr:   false,
+		},
+		"wildcard does not span a multi-valued RDN: denied": {
+			patterns: []string{"CN=*"},
+			dn:       "CN=x+O=Evil",
+			expErr:   true,
+		},
+		"multi-valued RDN: allowed": {
+			patterns: []string{"CN=*+UID=*,O=Acme"},
+			dn:       "CN=foo+UID=1000,O=Acme",
+			expErr:   false,
+		},
+		"multi-valued RDN attributes in any order: allowed": {
+			patterns: []string{"CN=*+UID=*,O=Acme"},
+			dn:       "UID=1000+CN=foo,O=Acme",
+			expErr:   false,
+		},
+		"multi-valued RDN attributes matched by distinct patterns: allowed": {
+			patterns: []string{"CN=*+CN=foo"},
+			dn:       "CN=foo+CN=bar",
+			expErr:   false,
+		},
+		"multi-valued RDN attribute matched twice: denied": {
+			patterns: []string{"CN=*+UID=*"},
+			dn:       "CN=foo+CN=bar",
+			expErr:   true,
+		},
+		"multi-valued RDN attribute value differs: denied": {
+			patterns: []string{"CN=foo+UID=1000"},
+			dn:       "UID=1001+CN=foo",
+			expErr:   true,
+		},
+		"attribute types differ in case: allowed": {
+			patterns: []string{"cn=*+uid=1000,o=Acme"},
+			dn:       "UID=1000+CN=foo,O=Acme",
+			expErr:   false,
+		},
+		"attribute values differ in case: denied": {
+			patterns: []string{"CN=foo,O=acme"},
+			dn:       "CN=foo,O=Acme",
+			expErr:   true,
+		},
+		"escaped plus within a value: allowed": {
+			patterns: []string{"CN=*"},
+			dn:       `CN=x\+O=Evil`,
+			expErr:   false,
+		},
+		"attribute type differs: denied": {
+			patterns: []string{"CN=*,O=Acme"},
+			dn:       "UID=foo,O=Acme",
+			expErr:   true,
+		},
+		"wildcard does not match the attribute type: denied": {
+			patterns: []string{"*=foo,O=Acme"},
+			dn:       "UID=foo,O=Acme",
+			expErr:   true,
+		},
+		"second pattern matches: allowed": {
+			patterns: []string{"CN=*,O=Acme,C=GB", "CN=*,OU=*,O=Acme,C=GB"},
+			dn:       "CN=foo,OU=payments,O=Acme,C=GB",
+			expErr:   false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			DistinguishedNames(&el, field.NewPath("spec"), &test.patterns, test.dn)
+			if (len(el) > 0) != test.expErr {
+				t.Errorf("unexpected errors (%v, %q): exp=%t got=%v",
+					test.patterns, test.dn, test.expErr, el)
+			}
+		})
+	}
+}
+
+func TestIssuerRef(t *testing.T) {
+	tests := map[string]struct {
+		policy cmpolicy.PolicyIssuer
+		ref    cmmeta.ObjectReference
+		exp    bool
+	}{
+		"exact match": {
+			policy: cmpolicy.PolicyIssuer{Name: "ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+			ref:    cmmeta.ObjectReference{Name: "ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+			exp:    true,
+		},
+		"unset request kind and group default to Issuer of cert-manager.io": {
+			policy: cmpolicy.PolicyIssuer{Name: "ca", Kind: "Issuer", Group: "cert-manager.io"},
+			ref:    cmmeta.ObjectReference{Name: "ca"},
+			exp:    true,
+		},
+		"unset policy kind does not match ClusterIssuer": {
+			policy: cmpolicy.PolicyIssuer{Name: "ca"},
+			ref:    cmmeta.ObjectReference{Name: "ca", Kind: "ClusterIssuer"},
+			exp:    false,
+		},
+		"unset policy name matches any name": {
+			policy: cmpolicy.PolicyIssuer{Kind: "ClusterIssuer"},
+			ref:    cmmeta.ObjectReference{Name: "ca", Kind: "ClusterIssuer"},
+			exp:    true,
+		},
+		"wildcard name and kind": {
+			policy: cmpolicy.PolicyIssuer{Name: "team-*", Kind: "*"},
+			ref:    cmmeta.ObjectReference{Name: "team-a", Kind: "ClusterIssuer"},
+			exp:    true,
+		},
+		"different group": {
+			policy: cmpolicy.PolicyIssuer{Name: "ca", Kind: "Issuer"},
+			ref:    cmmeta.ObjectReference{Name: "ca", Kind: "Issuer", Group: "example.com"},
+			exp:    false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IssuerRef(test.policy, test.ref); got != test.exp {
+				t.Errorf("unexpected match (%+v, %+v): exp=%t got=%t",
+					test.policy, test.ref, test.exp, got)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	hour := &metav1.Duration{Duration: time.Hour}
+	day := &metav1.Duration{Duration: time.Hour * 24}
+
+	tests := map[string]struct {
+		min, max *metav1.Duration
+		request  *metav1.Duration
+		expErr   bool
+	}{
+		"within bounds": {
+			min: hour, max: day, request: hour, expErr: false,
+		},
+		"below min": {
+			min: day, max: nil, request: hour, expErr: true,
+		},
+		"above max": {
+			min: nil, max: hour, request: day, expErr: true,
+		},
+		"no request duration": {
+			min: hour, max: day, request: nil, expErr: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			MinDuration(&el, field.NewPath("spec", "minDuration"), test.min, test.request)
+			MaxDuration(&el, field.NewPath("spec", "maxDuration"), test.max, test.request)
+			if (len(el) > 0) != test.expErr {
+				t.Errorf("unexpected errors: exp=%t got=%v", test.expErr, el)
+			}
+		})
+	}
+}
+
+func TestDenied(t *testing.T) {
+	denied := &[]string{"login.example.com", "*.admin.example.com"}
+
+	tests := map[string]struct {
+		policy  *[]string
+		request []string
+		expErrs int
+	}{
+		"no denied patterns": {
+			policy: nil, request: []string{"login.example.com"}, expErrs: 0,
+		},
+		"no denied values": {
+			policy: denied, request: []string{"www.example.com", "admin.example.com"}, expErrs: 0,
+		},
+		"exact denied value": {
+			policy: denied, request: []string{"www.example.com", "login.example.com"}, expErrs: 1,
+		},
+		"wildcard denied values": {
+			policy: denied, request: []string{"a.admin.example.com", "b.admin.example.com"}, expErrs: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			Denied(&el, field.NewPath("spec", "deniedDNSNames"), test.policy, test.request)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestDeniedDNSNames(t *testing.T) {
+	denied := &[]string{"login.example.com", "*.admin.example.com"}
+
+	tests := map[string]struct {
+		policy  *[]string
+		request []string
+		expErrs int
+	}{
+		"no denied patterns": {
+			policy: nil, request: []string{"*.example.com"}, expErrs: 0,
+		},
+		"no denied values": {
+			policy: denied, request: []string{"www.example.com", "admin.example.com"}, expErrs: 0,
+		},
+		"upper case denied value": {
+			policy: denied, request: []string{"LOGIN.Example.com", "login.example.com."}, expErrs: 2,
+		},
+		"upper case denied pattern": {
+			policy: &[]string{"LOGIN.example.com"}, request: []string{"login.example.com"}, expErrs: 1,
+		},
+		"wildcard covering a denied value": {
+			policy: denied, request: []string{"*.example.com", "*.EXAMPLE.com"}, expErrs: 2,
+		},
+		"wildcard covering a denied wildcard pattern": {
+			policy: denied, request: []string{"*.admin.example.com", "*.x.admin.example.com"}, expErrs: 2,
+		},
+		"wildcard covering only other names": {
+			policy: denied, request: []string{"*.www.example.com", "*.com"}, expErrs: 0,
+		},
+		"wildcard covering names of a wildcard pattern within a label": {
+			policy: &[]string{"login-*.example.com"}, request: []string{"*.example.com"}, expErrs: 1,
+		},
+		"wildcard does not cover its own domain": {
+			policy: &[]string{"example.com"}, request: []string{"*.example.com"}, expErrs: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			DeniedDNSNames(&el, field.NewPath("spec", "deniedDNSNames"), test.policy, test.request)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	max := 3
+
+	tests := map[string]struct {
+		check   func(el *field.ErrorList)
+		expErrs int
+	}{
+		"size below max": {
+			check:   func(el *field.ErrorList) { MaxSize(el, field.NewPath("max"), &max, 2) },
+			expErrs: 0,
+		},
+		"size at max": {
+			check:   func(el *field.ErrorList) { MaxSize(el, field.NewPath("max"), &max, 3) },
+			expErrs: 0,
+		},
+		"size above max": {
+			check:   func(el *field.ErrorList) { MaxSize(el, field.NewPath("max"), &max, 4) },
+			expErrs: 1,
+		},
+		"length at max": {
+			check:   func(el *field.ErrorList) { MaxLength(el, field.NewPath("max"), &max, []string{"abc", ""}) },
+			expErrs: 0,
+		},
+		"length above max": {
+			check:   func(el *field.ErrorList) { MaxLength(el, field.NewPath("max"), &max, []string{"abc", "abcd", "abcde"}) },
+			expErrs: 2,
+		},
+		"multibyte length at max": {
+			check:   func(el *field.ErrorList) { MaxLength(el, field.NewPath("max"), &max, []string{"日本語"}) },
+			expErrs: 0,
+		},
+		"multibyte length above max": {
+			check:   func(el *field.ErrorList) { MaxLength(el, field.NewPath("max"), &max, []string{"日本語版"}) },
+			expErrs: 1,
+		},
+		"label length at max": {
+			check:   func(el *field.ErrorList) { MaxLabelLength(el, field.NewPath("max"), &max, []string{"abc.abc.abc"}) },
+			expErrs: 0,
+		},
+		"label length above max": {
+			check: func(el *field.ErrorList) {
+				MaxLabelLength(el, field.NewPath("max"), &max, []string{"abc.abcd.abc", "abcd.abcd"})
+			},
+			expErrs: 2,
+		},
+		"multibyte label length at max": {
+			check:   func(el *field.ErrorList) { MaxLabelLength(el, field.NewPath("max"), &max, []string{"日本語.jp"}) },
+			expErrs: 0,
+		},
+		"no max": {
+			check:   func(el *field.ErrorList) { MaxLength(el, field.NewPath("max"), nil, []string{"abcdef"}) },
+			expErrs: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			test.check(&el)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestNameConstraints(t *testing.T) {
+	_, permittedNet, _ := net.ParseCIDR("10.0.0.0/8")
+	ca := &x509.Certificate{
+		PermittedDNSDomains:     []string{"example.com", ".internal.net"},
+		ExcludedDNSDomains:      []string{"admin.example.com"},
+		PermittedIPRanges:       []*net.IPNet{permittedNet},
+		PermittedEmailAddresses: []string{"example.com", "root@internal.net"},
+		PermittedURIDomains:     []string{".example.com"},
+	}
+
+	mustParseURL := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	tests := map[string]struct {
+		request *x509.CertificateRequest
+		expErr  bool
+	}{
+		"permitted domain and subdomain": {
+			request: &x509.CertificateRequest{DNSNames: []string{"example.com", "foo.example.com", "*.example.com"}},
+			expErr:  false,
+		},
+		"leading period only permits subdomains": {
+			request: &x509.CertificateRequest{DNSNames: []string{"internal.net"}},
+			expErr:  true,
+		},
+		"subdomain of leading period constraint": {
+			request: &x509.CertificateRequest{DNSNames: []string{"foo.internal.net"}},
+			expErr:  false,
+		},
+		"suffix which is not a subdomain": {
+			request: &x509.CertificateRequest{DNSNames: []string{"notexample.com"}},
+			expErr:  true,
+		},
+		"excluded subdomain": {
+			request: &x509.CertificateRequest{DNSNames: []string{"login.admin.example.com"}},
+			expErr:  true,
+		},
+		"permitted IP range": {
+			request: &x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("10.1.2.3")}},
+			expErr:  false,
+		},
+		"IP outside of permitted range": {
+			request: &x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("192.168.0.1")}},
+			expErr:  true,
+		},
+		"IPv6 does not match IPv4 range": {
+			request: &x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("::1")}},
+			expErr:  true,
+		},
+		"email on permitted host and permitted mailbox": {
+			request: &x509.CertificateRequest{EmailAddresses: []string{"foo@example.com", "root@internal.net"}},
+			expErr:  false,
+		},
+		"email on subdomain of host constraint": {
+			request: &x509.CertificateRequest{EmailAddresses: []string{"foo@mail.example.com"}},
+			expErr:  true,
+		},
+		"URI on permitted domain": {
+			request: &x509.CertificateRequest{URIs: []*url.URL{mustParseURL("spiffe://foo.example.com/ns/default")}},
+			expErr:  false,
+		},
+		"URI outside of permitted domain": {
+			request: &x509.CertificateRequest{URIs: []*url.URL{mustParseURL("spiffe://example.org/ns/default")}},
+			expErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			NameConstraints(&el, field.NewPath("spec"), ca, test.request)
+			if (len(el) > 0) != test.expErr {
+				t.Errorf("unexpected errors: exp=%t got=%v", test.expErr, el)
+			}
+		})
+	}
+}
+
+func TestClassifyIPAddress(t *testing.T) {
+	tests := map[string][]cmpolicy.IPAddressCategory{
+		"0.0.0.0":          {cmpolicy.IPAddressCategoryUnspecified},
+		"::":               {cmpolicy.IPAddressCategoryUnspecified},
+		"127.0.0.1":        {cmpolicy.IPAddressCategoryLoopback},
+		"::1":              {cmpolicy.IPAddressCategoryLoopback},
+		"::ffff:127.0.0.1": {cmpolicy.IPAddressCategoryLoopback},
+		"169.254.169.254":  {cmpolicy.IPAddressCategoryCloudMetadata, cmpolicy.IPAddressCategoryLinkLocal},
+		"fd00:ec2::254":    {cmpolicy.IPAddressCategoryCloudMetadata, cmpolicy.IPAddressCategoryPrivate},
+		"100.100.100.200":  {cmpolicy.IPAddressCategoryCloudMetadata, cmpolicy.IPAddressCategoryShared},
+		"169.254.1.1":      {cmpolicy.IPAddressCategoryLinkLocal},
+		"fe80::1":          {cmpolicy.IPAddressCategoryLinkLocal},
+		"224.0.0.1":        {cmpolicy.IPAddressCategoryMulticast},
+		"ff02::1":          {cmpolicy.IPAddressCategoryMulticast},
+		"10.0.0.1":         {cmpolicy.IPAddressCategoryPrivate},
+		"192.168.1.1":      {cmpolicy.IPAddressCategoryPrivate},
+		"fd12:3456::1":     {cmpolicy.IPAddressCategoryPrivate},
+		"100.64.0.1":       {cmpolicy.IPAddressCategoryShared},
+		"100.127.255.254":  {cmpolicy.IPAddressCategoryShared},
+		"100.128.0.1":      {cmpolicy.IPAddressCategoryPublic},
+		"8.8.8.8":          {cmpolicy.IPAddressCategoryPublic},
+		"2001:4860::8888":  {cmpolicy.IPAddressCategoryPublic},
+		"255.255.255.255":  {cmpolicy.IPAddressCategoryOther},
+	}
+
+	for ip, exp := range tests {
+		t.Run(ip, func(t *testing.T) {
+			if categories := ClassifyIPAddress(net.ParseIP(ip)); !reflect.DeepEqual(categories, exp) {
+				t.Errorf("unexpected categories (%s): exp=%v got=%v", ip, exp, categories)
+			}
+		})
+	}
+}
+
+func TestIPAddressCategories(t *testing.T) {
+	linkLocal := &[]cmpolicy.IPAddressCategory{cmpolicy.IPAddressCategoryLinkLocal}
+
+	tests := map[string]struct {
+		policy  *cmpolicy.PolicyIPAddresses
+		ip      string
+		expErrs int
+	}{
+		"denied link local denies cloud metadata": {
+			policy: &cmpolicy.PolicyIPAddresses{DeniedCategories: linkLocal}, ip: "169.254.169.254", expErrs: 1,
+		},
+		"allowed link local does not allow cloud metadata": {
+			policy: &cmpolicy.PolicyIPAddresses{AllowedCategories: linkLocal}, ip: "169.254.169.254", expErrs: 1,
+		},
+		"allowed link local": {
+			policy: &cmpolicy.PolicyIPAddresses{AllowedCategories: linkLocal}, ip: "169.254.1.1", expErrs: 0,
+		},
+		"denied link local does not deny shared": {
+			policy: &cmpolicy.PolicyIPAddresses{DeniedCategories: linkLocal}, ip: "100.64.0.1", expErrs: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			IPAddressCategories(&el, field.NewPath("spec", "ipAddresses"), test.policy, []net.IP{net.ParseIP(test.ip)})
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
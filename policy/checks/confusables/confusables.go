@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package confusables
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// data is confusables.txt from Unicode Technical Standard #39, version 13.0.0.
+// Characters which are not in the data, but are compatibility equivalents of
+// characters which are, such as the mathematical alphanumeric symbols, are
+// mapped through their compatibility decomposition.
+//
+//go:embed confusables.txt
+var data []byte
+
+// prototypes maps a confusable rune to its prototype string.
+var prototypes map[rune]string
+
+func init() {
+	var err error
+	prototypes, err = parse(data)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse embedded confusables data: %s", err))
+	}
+}
+
+// LoadFile replaces the embedded data with the data in the format of
+// confusables.txt read from the given file path, such as a newer version of
+// the file published with TR39. Must be called before any skeletons are
+// computed.
+func LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	m, err := parse(data)
+	if err != nil {
+		return err
+	}
+
+	prototypes = m
+	return nil
+}
+
+// parse parses data in the format of confusables.txt.
+func parse(data []byte) (map[rune]string, error) {
+	m := make(map[rune]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			continue
+		}
+
+		source, err := parseCodePoints(fields[0])
+		if err != nil || len([]rune(source)) != 1 {
+			return nil, fmt.Errorf("invalid source %q", fields[0])
+		}
+		target, err := parseCodePoints(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %q", fields[1])
+		}
+
+		m[[]rune(source)[0]] = target
+	}
+
+	return m, scanner.Err()
+}
+
+// parseCodePoints parses a space separated list of hex code points.
+func parseCodePoints(s string) (string, error) {
+	var sb strings.Builder
+	for _, cp := range strings.Fields(s) {
+		r, err := strconv.ParseUint(cp, 16, 32)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteRune(rune(r))
+	}
+	return sb.String(), nil
+}
+
+// Skeleton returns the TR39 skeleton of the given string. Two strings are
+// visually confusable if they have the same skeleton. Skeletons are case
+// sensitive, since prototypes may be upper case letters.
+func Skeleton(s string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if prototype, ok := prototypes[r]; ok {
+			sb.WriteString(prototype)
+			continue
+		}
+
+		// Fall back to the compatibility equivalent of runes which are not in
+		// the data, e.g. MATHEMATICAL BOLD SMALL A for a.
+		if compat := norm.NFKD.String(string(r)); compat != string(r) {
+			for _, c := range compat {
+				if prototype, ok := prototypes[c]; ok {
+					sb.WriteString(prototype)
+				} else {
+					sb.WriteRune(c)
+				}
+			}
+			continue
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return norm.NFD.String(sb.String())
+}
+
+// FoldedSkeleton returns the skeleton of the given string for case
+// insensitive comparison, such as of DNS names. The string is lower cased
+// before and after computing its skeleton, so that upper case prototypes are
+// confusable with their lower case letters.
+func FoldedSkeleton(s string) string {
+	return Skeleton(strings.ToLower(Skeleton(strings.ToLower(s))))
+}
+
+// allowedScriptSets are the combinations of scripts which may be mixed within
+// a single label, as per the "Highly Restrictive" level of TR39 section 5.2.
+var allowedScriptSets = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// IsMixedScript returns true if the given label contains characters from more
+// than one script, ignoring the Common and Inherited scripts, except for the
+// combinations of scripts commonly used together to write CJK languages.
+func IsMixedScript(label string) bool {
+	scripts := make(map[string]bool)
+	for _, r := range label {
+		if script := scriptOf(r); len(script) > 0 {
+			scripts[script] = true
+		}
+	}
+
+	if len(scripts) <= 1 {
+		return false
+	}
+
+	for _, set := range allowedScriptSets {
+		if isSubset(scripts, set) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// scriptOf returns the name of the script of the given rune, or an empty
+// string if the rune is in the Common or Inherited script.
+func scriptOf(r rune) string {
+	if unicode.In(r, unicode.Common, unicode.Inherited) {
+		return ""
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+func isSubset(scripts map[string]bool, set []string) bool {
+	for script := range scripts {
+		var found bool
+		for _, s := range set {
+			if s == script {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package confusables
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		exp  bool
+	}{
+		"same string: confusable": {
+			a:   "paypal-internal",
+			b:   "paypal-internal",
+			exp: true,
+		},
+		"cyrillic a: confusable": {
+			a:   "pаypal-internal",
+			b:   "paypal-internal",
+			exp: true,
+		},
+		"greek omicron: confusable": {
+			a:   "gοοgle",
+			b:   "google",
+			exp: true,
+		},
+		"rn and m: confusable": {
+			a:   "acrne",
+			b:   "acme",
+			exp: true,
+		},
+		"mathematical alphanumeric: confusable": {
+			a:   "p\U0001D41Aypal",
+			b:   "paypal",
+			exp: true,
+		},
+		"cherokee letter and upper case latin: confusable": {
+			a:   "\u13A0NS",
+			b:   "DNS",
+			exp: true,
+		},
+		"greek capital iota and l: confusable": {
+			a:   "\u0399ab",
+			b:   "lab",
+			exp: true,
+		},
+		"non-latin prototype: confusable": {
+			a:   "\u05AD",
+			b:   "\u0596",
+			exp: true,
+		},
+		"capital I and l: confusable": {
+			a:   "Il",
+			b:   "ll",
+			exp: true,
+		},
+		"upper case: not confusable": {
+			a:   "PayPal",
+			b:   "paypal",
+			exp: false,
+		},
+		"different string: not confusable": {
+			a:   "paypal",
+			b:   "paypai",
+			exp: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if confusable := Skeleton(test.a) == Skeleton(test.b); confusable != test.exp {
+				t.Errorf("unexpected confusable (%q, %q): exp=%t got=%t",
+					test.a, test.b, test.exp, confusable)
+			}
+		})
+	}
+}
+
+func TestFoldedSkeleton(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		exp  bool
+	}{
+		"upper case: confusable": {
+			a:   "PayPal",
+			b:   "paypal",
+			exp: true,
+		},
+		"digit zero and upper case prototype: confusable": {
+			a:   "g00gle",
+			b:   "google",
+			exp: true,
+		},
+		"mathematical alphanumeric: confusable": {
+			a:   "p\U0001D41Aypal",
+			b:   "paypal",
+			exp: true,
+		},
+		"different string: not confusable": {
+			a:   "paypal",
+			b:   "paypai",
+			exp: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if confusable := FoldedSkeleton(test.a) == FoldedSkeleton(test.b); confusable != test.exp {
+				t.Errorf("unexpected confusable (%q, %q): exp=%t got=%t",
+					test.a, test.b, test.exp, confusable)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	defer func(m map[rune]string) { prototypes = m }(prototypes)
+
+	path := filepath.Join(t.TempDir(), "confusables.txt")
+	if err := os.WriteFile(path, []byte("\ufeff# comment\n0071 ;\t0067 ;\tMA\t# ( q → g )\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if Skeleton("q") != Skeleton("g") {
+		t.Errorf("expected loaded data to be used")
+	}
+	if Skeleton("\u0430") == Skeleton("a") {
+		t.Errorf("expected embedded data to be replaced")
+	}
+}
+
+func TestIsMixedScript(t *testing.T) {
+	tests := map[string]struct {
+		label string
+		exp   bool
+	}{
+		"latin with digits and hyphen": {
+			label: "foo-123",
+			exp:   false,
+		},
+		"cyrillic only": {
+			label: "пример",
+			exp:   false,
+		},
+		"latin with cyrillic a": {
+			label: "pаypal",
+			exp:   true,
+		},
+		"japanese with latin": {
+			label: "日本ごabc",
+			exp:   false,
+		},
+		"greek with latin": {
+			label: "gοogle",
+			exp:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if mixed := IsMixedScript(test.label); mixed != test.exp {
+				t.Errorf("unexpected mixed script (%q): exp=%t got=%t",
+					test.label, test.exp, mixed)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package publicsuffix
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// snapshot is a copy of https://publicsuffix.org/list/public_suffix_list.dat,
+// taken on 2023-02-09.
+//
+//go:embed public_suffix_list.dat
+var snapshot []byte
+
+// defaultList is the List parsed from the embedded snapshot.
+var defaultList *List
+
+func init() {
+	list, err := Parse(bytes.NewReader(snapshot))
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse embedded public suffix list: %s", err))
+	}
+	defaultList = list
+}
+
+// Default returns the List used for policy evaluation. This is the list parsed
+// from the snapshot embedded in the binary, unless overridden with SetDefault.
+func Default() *List {
+	return defaultList
+}
+
+// SetDefault overrides the List returned by Default. It must be called before
+// any policy is evaluated.
+func SetDefault(l *List) {
+	defaultList = l
+}
+
+// ParseFile parses a list in the format of public_suffix_list.dat from the
+// given file path.
+func ParseFile(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// List is a parsed Public Suffix List, containing both the ICANN and private
+// sections of the list. All rules are stored in their ASCII (punycode) form.
+type List struct {
+	rules      map[string]bool
+	wildcards  map[string]bool
+	exceptions map[string]bool
+}
+
+// Parse parses a list in the format of public_suffix_list.dat.
+func Parse(r io.Reader) (*List, error) {
+	l := &List{
+		rules:      make(map[string]bool),
+		wildcards:  make(map[string]bool),
+		exceptions: make(map[string]bool),
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// Rules end at the first whitespace, and may be followed by comments.
+		line := strings.Fields(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line[0], "//") {
+			continue
+		}
+
+		rule := line[0]
+		rules := l.rules
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			rule, rules = rule[1:], l.exceptions
+		case strings.HasPrefix(rule, "*."):
+			rule, rules = rule[2:], l.wildcards
+		}
+
+		ascii, err := idna.ToASCII(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", line[0], err)
+		}
+		rules[strings.ToLower(ascii)] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// PublicSuffix returns the public suffix of the given ASCII domain, using the
+// prevailing rule of the list. If no rule matches, the top level label is the
+// public suffix, as per the implicit "*" rule.
+func (l *List) PublicSuffix(domain string) string {
+	labels := strings.Split(normalise(domain), ".")
+
+	// Walk from the longest suffix to the shortest, so the first rule found is
+	// the prevailing one.
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+		if l.exceptions[suffix] {
+			return strings.Join(labels[i+1:], ".")
+		}
+		if l.rules[suffix] {
+			return suffix
+		}
+		if i+1 < len(labels) && l.wildcards[strings.Join(labels[i+1:], ".")] {
+			return suffix
+		}
+	}
+
+	return labels[len(labels)-1]
+}
+
+// RegistrableDomain returns the registrable domain of the given domain, that
+// is the public suffix plus one more label. Returns false if the domain is
+// itself a public suffix.
+func (l *List) RegistrableDomain(domain string) (string, bool) {
+	domain = normalise(domain)
+	suffix := l.PublicSuffix(domain)
+	if suffix == domain {
+		return "", false
+	}
+
+	rest := strings.TrimSuffix(domain, "."+suffix)
+	return rest[strings.LastIndex(rest, ".")+1:] + "." + suffix, true
+}
+
+// IsPublicSuffix returns true if the given domain is itself a public suffix.
+func (l *List) IsPublicSuffix(domain string) bool {
+	return l.PublicSuffix(domain) == normalise(domain)
+}
+
+// normalise lower cases the domain and removes any trailing dot.
+func normalise(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
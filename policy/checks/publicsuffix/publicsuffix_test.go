@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package publicsuffix
+
+import (
+	"testing"
+)
+
+func TestPublicSuffix(t *testing.T) {
+	tests := map[string]struct {
+		domain string
+		exp    string
+	}{
+		"top level domain": {
+			domain: "com",
+			exp:    "com",
+		},
+		"registrable domain": {
+			domain: "example.com",
+			exp:    "com",
+		},
+		"multi label suffix": {
+			domain: "foo.example.co.uk",
+			exp:    "co.uk",
+		},
+		"private section suffix": {
+			domain: "foo.github.io",
+			exp:    "github.io",
+		},
+		"wildcard rule": {
+			domain: "foo.bar.kobe.jp",
+			exp:    "bar.kobe.jp",
+		},
+		"exception rule": {
+			domain: "www.city.kobe.jp",
+			exp:    "kobe.jp",
+		},
+		"internationalised rule in punycode": {
+			domain: "example.xn--55qx5d.cn",
+			exp:    "xn--55qx5d.cn",
+		},
+		"unknown top level domain": {
+			domain: "foo.example.internal",
+			exp:    "internal",
+		},
+		"upper case with trailing dot": {
+			domain: "Example.CO.UK.",
+			exp:    "co.uk",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if suffix := Default().PublicSuffix(test.domain); suffix != test.exp {
+				t.Errorf("unexpected public suffix (%q): exp=%q got=%q",
+					test.domain, test.exp, suffix)
+			}
+		})
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := map[string]struct {
+		domain string
+		exp    string
+		expOK  bool
+	}{
+		"public suffix": {
+			domain: "co.uk",
+			exp:    "",
+			expOK:  false,
+		},
+		"registrable domain": {
+			domain: "example.co.uk",
+			exp:    "example.co.uk",
+			expOK:  true,
+		},
+		"subdomain": {
+			domain: "foo.bar.example.com",
+			exp:    "example.com",
+			expOK:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			domain, ok := Default().RegistrableDomain(test.domain)
+			if domain != test.exp || ok != test.expOK {
+				t.Errorf("unexpected registrable domain (%q): exp=(%q, %t) got=(%q, %t)",
+					test.domain, test.exp, test.expOK, domain, ok)
+			}
+		})
+	}
+}
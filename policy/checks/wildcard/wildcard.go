@@ -48,19 +48,40 @@ func Matchs(pattern, str string) bool {
 	return matchRunes([]rune(pattern), []rune(str))
 }
 
+// matchRunes matches str against pattern, where '*' in pattern matches any
+// sequence of runes. When a mismatch occurs, only the most recent '*' is
+// backtracked to, which bounds matching to O(len(pattern)*len(str)) rather
+// than exponential time in the number of '*'s.
 func matchRunes(pattern, str []rune) bool {
-	for len(pattern) > 0 {
-		switch pattern[0] {
-		case '*':
-			return matchRunes(pattern[1:], str) || (len(str) > 0 && matchRunes(pattern, str[1:]))
+	var p, s int
+	// Index of the last '*' seen in pattern, and the index in str it is
+	// currently matched up to. starP is -1 until a '*' has been seen.
+	starP, starS := -1, 0
+
+	for s < len(str) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			starP, starS = p, s
+			p++
+
+		case p < len(pattern) && pattern[p] == str[s]:
+			p++
+			s++
+
+		case starP != -1:
+			// Let the last '*' consume one more rune and try again.
+			starS++
+			p, s = starP+1, starS
 
 		default:
-			if len(str) == 0 || str[0] != pattern[0] {
-				return false
-			}
+			return false
 		}
-		str = str[1:]
-		pattern = pattern[1:]
 	}
-	return len(str) == 0 && len(pattern) == 0
+
+	// Any remaining pattern must only be '*'s, which match the empty string.
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
 }
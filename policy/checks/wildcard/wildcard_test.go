@@ -18,6 +18,7 @@ package wildcard
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -294,6 +295,21 @@ func TestMatchs(t *testing.T) {
 			text:    "cert-manager.io",
 			exp:     false,
 		},
+		"pattern with consecutive wildcards: true": {
+			pattern: "cert-**.io",
+			text:    "cert-manager.io",
+			exp:     true,
+		},
+		"pattern requiring backtracking: true": {
+			pattern: "*.foo.*.io",
+			text:    "a.foo.b.foo.c.io",
+			exp:     true,
+		},
+		"many wildcards against a long non-matching text: false": {
+			pattern: strings.Repeat("*a", 100) + "b",
+			text:    strings.Repeat("a", 10000),
+			exp:     false,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// NamespaceCombinationAnnotationKey is the annotation on a Namespace
+// overriding how the policies bound to requests in that Namespace are
+// combined. Without it, bound policies are combined with the mode configured
+// for the approver, AnyOf by default. Its value must be the name of a
+// Combination, as accepted by ParseCombination; requests in a Namespace with
+// any other value are denied.
+const NamespaceCombinationAnnotationKey = "policy.cert-manager.io/combination"
+
+// Combination is how the policies bound to a request are combined into a
+// single decision.
+type Combination string
+
+const (
+	// CombinationAnyOf approves a request if any bound policy approves it.
+	CombinationAnyOf Combination = "AnyOf"
+
+	// CombinationAllOf approves a request if every bound policy approves it.
+	CombinationAllOf Combination = "AllOf"
+
+	// CombinationLayered approves a request if every bound policy of each tier
+	// approves it, and at least one bound policy of the final tier. Tiers are
+	// set by the tier field of each CertificateRequestPolicy.
+	CombinationLayered Combination = "Layered"
+)
+
+// ParseCombination returns the Combination of the given name, which is one of
+// AnyOf, AllOf or Layered. It parses both the --policy-combination flag and
+// the value of the NamespaceCombinationAnnotationKey annotation.
+func ParseCombination(name string) (Combination, error) {
+	switch c := Combination(name); c {
+	case CombinationAnyOf, CombinationAllOf, CombinationLayered:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown combination %q, must be one of %q, %q or %q",
+			name, CombinationAnyOf, CombinationAllOf, CombinationLayered)
+	}
+}
+
+// combination returns how the policies bound to requests in the namespace are
+// combined. Returns a reason if the namespace has an invalid combination
+// annotation.
+func (p *Policy) combination(ctx context.Context, namespace string) (Combination, string, error) {
+	combination := p.opts.Combination
+	if len(combination) == 0 {
+		combination = CombinationAnyOf
+	}
+
+	ns := new(corev1.Namespace)
+	if err := p.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return combination, "", nil
+		}
+		return "", "", err
+	}
+
+	value, ok := ns.Annotations[NamespaceCombinationAnnotationKey]
+	if !ok {
+		return combination, "", nil
+	}
+
+	combination, err := ParseCombination(value)
+	if err != nil {
+		return "", fmt.Sprintf("Namespace %q has an invalid %q annotation: %s", namespace, NamespaceCombinationAnnotationKey, err), nil
+	}
+
+	return combination, "", nil
+}
+
+// evaluateAnyOf approves the request if any of the policies approve it.
+func (p *Policy) evaluateAnyOf(ctx context.Context, crps []*cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (bool, string, error) {
+	approvedBy, policyErrors, err := p.anyOf(ctx, crps, cr)
+	if err != nil {
+		return false, ErrorMessage, err
+	}
+	if len(approvedBy) > 0 {
+		return true, fmt.Sprintf("Approved by CertificateRequestPolicy %q", approvedBy), nil
+	}
+
+	// Return with all policies that we consulted, and their errors to why the
+	// request was denied.
+	return false, fmt.Sprintf("No policy approved this request: %v", policyErrors), nil
+}
+
+// evaluateAllOf approves the request if all of the policies approve it.
+func (p *Policy) evaluateAllOf(ctx context.Context, crps []*cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (bool, string, error) {
+	policyErrors, err := p.allOf(ctx, crps, cr)
+	if err != nil {
+		return false, ErrorMessage, err
+	}
+	if len(policyErrors) > 0 {
+		return false, fmt.Sprintf("Not all bound policies approved this request: %v", policyErrors), nil
+	}
+
+	return true, fmt.Sprintf("Approved by CertificateRequestPolicies %q", policyNames(crps)), nil
+}
+
+// evaluateLayered approves the request if all of the policies of each tier
+// approve it, and at least one of the policies of the final tier.
+func (p *Policy) evaluateLayered(ctx context.Context, crps []*cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (bool, string, error) {
+	tiers := make(map[int32][]*cmpolicy.CertificateRequestPolicy)
+	for _, crp := range crps {
+		var tier int32
+		if crp.Spec.Tier != nil {
+			tier = *crp.Spec.Tier
+		}
+		tiers[tier] = append(tiers[tier], crp)
+	}
+
+	order := make([]int32, 0, len(tiers))
+	for tier := range tiers {
+		order = append(order, tier)
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	var approvedBy []string
+	for _, tier := range order[:len(order)-1] {
+		policyErrors, err := p.allOf(ctx, tiers[tier], cr)
+		if err != nil {
+			return false, ErrorMessage, err
+		}
+		if len(policyErrors) > 0 {
+			return false, fmt.Sprintf("Not all bound policies of tier %d approved this request: %v", tier, policyErrors), nil
+		}
+		approvedBy = append(approvedBy, policyNames(tiers[tier])...)
+	}
+
+	final := order[len(order)-1]
+	name, policyErrors, err := p.anyOf(ctx, tiers[final], cr)
+	if err != nil {
+		return false, ErrorMessage, err
+	}
+	if len(name) == 0 {
+		return false, fmt.Sprintf("No policy of tier %d approved this request: %v", final, policyErrors), nil
+	}
+	approvedBy = append(approvedBy, name)
+
+	return true, fmt.Sprintf("Approved by CertificateRequestPolicies %q", approvedBy), nil
+}
+
+// anyOf returns the name of the first policy which approves the request. If
+// none approve it, returns the reason each policy denied it.
+func (p *Policy) anyOf(ctx context.Context, crps []*cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (string, map[string]string, error) {
+	policyErrors := make(map[string]string)
+	for _, crp := range crps {
+		denied, err := p.evaluatePolicy(ctx, crp, cr)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(denied) == 0 {
+			return crp.Name, nil, nil
+		}
+
+		// Collect policy errors by the CertificateRequestPolicy name, so errors
+		// can be bubbled to the CertificateRequest condition
+		policyErrors[crp.Name] = denied
+	}
+	return "", policyErrors, nil
+}
+
+// allOf returns the reason each policy which denies the request denied it.
+func (p *Policy) allOf(ctx context.Context, crps []*cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (map[string]string, error) {
+	policyErrors := make(map[string]string)
+	for _, crp := range crps {
+		denied, err := p.evaluatePolicy(ctx, crp, cr)
+		if err != nil {
+			return nil, err
+		}
+		if len(denied) > 0 {
+			policyErrors[crp.Name] = denied
+		}
+	}
+	return policyErrors, nil
+}
+
+// evaluatePolicy returns the reason the policy denies the request, or an
+// empty string if the policy approves it.
+func (p *Policy) evaluatePolicy(ctx context.Context, crp *cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest) (string, error) {
+	var el field.ErrorList
+	if err := p.EvaluateCertificateRequest(ctx, &el, crp, cr); err != nil {
+		return "", err
+	}
+	if len(el) == 0 {
+		return "", nil
+	}
+	return denialReason(crp.Spec.Denial, el), nil
+}
+
+// policyNames returns the names of the policies.
+func policyNames(crps []*cmpolicy.CertificateRequestPolicy) []string {
+	names := make([]string, 0, len(crps))
+	for _, crp := range crps {
+		names = append(names, crp.Name)
+	}
+	return names
+}
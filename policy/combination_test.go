@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestEvaluateCombination(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, cmapi.AddToScheme, cmpolicy.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	crp := func(name string, tier int32, dnsName string) runtime.Object {
+		return &cmpolicy.CertificateRequestPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: cmpolicy.CertificateRequestPolicySpec{
+				Tier:              &tier,
+				PolicyConstraints: cmpolicy.PolicyConstraints{AllowedDNSNames: &[]string{dnsName}},
+			},
+		}
+	}
+
+	// The request is for foo.example.com, so "example" and "any" approve it,
+	// and "acme" denies it.
+	tests := map[string]struct {
+		combination Combination
+		annotation  string
+		policies    []runtime.Object
+		expApproved bool
+	}{
+		"AnyOf: one policy approves": {
+			combination: CombinationAnyOf,
+			policies:    []runtime.Object{crp("example", 0, "*.example.com"), crp("acme", 0, "*.acme.com")},
+			expApproved: true,
+		},
+		"AnyOf: no policy approves": {
+			combination: CombinationAnyOf,
+			policies:    []runtime.Object{crp("acme", 0, "*.acme.com")},
+			expApproved: false,
+		},
+		"AllOf: one policy denies": {
+			combination: CombinationAllOf,
+			policies:    []runtime.Object{crp("example", 0, "*.example.com"), crp("acme", 0, "*.acme.com")},
+			expApproved: false,
+		},
+		"AllOf: every policy approves": {
+			combination: CombinationAllOf,
+			policies:    []runtime.Object{crp("example", 0, "*.example.com"), crp("any", 0, "*")},
+			expApproved: true,
+		},
+		"Layered: lower tier approves, one policy of the final tier approves": {
+			combination: CombinationLayered,
+			policies:    []runtime.Object{crp("any", 0, "*"), crp("example", 1, "*.example.com"), crp("acme", 1, "*.acme.com")},
+			expApproved: true,
+		},
+		"Layered: tie in a lower tier, one policy denies": {
+			combination: CombinationLayered,
+			policies:    []runtime.Object{crp("example", 0, "*.example.com"), crp("acme", 0, "*.acme.com"), crp("any", 1, "*")},
+			expApproved: false,
+		},
+		"Layered: lower tier denies, final tier approves": {
+			combination: CombinationLayered,
+			policies:    []runtime.Object{crp("acme", -1, "*.acme.com"), crp("example", 5, "*.example.com")},
+			expApproved: false,
+		},
+		"Layered: every policy in a single tier is the final tier": {
+			combination: CombinationLayered,
+			policies:    []runtime.Object{crp("example", 0, "*.example.com"), crp("acme", 0, "*.acme.com")},
+			expApproved: true,
+		},
+		"Layered: no policy of the final tier approves": {
+			combination: CombinationLayered,
+			policies:    []runtime.Object{crp("any", 0, "*"), crp("acme", 1, "*.acme.com")},
+			expApproved: false,
+		},
+		"namespace annotation overrides the configured combination": {
+			combination: CombinationAnyOf,
+			annotation:  string(CombinationAllOf),
+			policies:    []runtime.Object{crp("example", 0, "*.example.com"), crp("acme", 0, "*.acme.com")},
+			expApproved: false,
+		},
+		"invalid namespace annotation denies": {
+			combination: CombinationAnyOf,
+			annotation:  "OneOf",
+			policies:    []runtime.Object{crp("example", 0, "*.example.com")},
+			expApproved: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+			if len(test.annotation) > 0 {
+				ns.Annotations = map[string]string{NamespaceCombinationAnnotationKey: test.annotation}
+			}
+			objects := append([]runtime.Object{ns}, test.policies...)
+			c := sarClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build(), allowed: true}
+
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test"},
+				Spec: cmapi.CertificateRequestSpec{
+					Username: "alice",
+					Request:  mustCSR(t, &x509.CertificateRequest{DNSNames: []string{"foo.example.com"}}),
+				},
+			}
+
+			approved, reason, err := New(c, Options{Combination: test.combination}).Evaluate(context.TODO(), cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if approved != test.expApproved {
+				t.Errorf("unexpected approval: exp=%t got=%t: %s", test.expApproved, approved, reason)
+			}
+		})
+	}
+}
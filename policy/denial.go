@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// denialReason returns the reason the policy denied the request, replacing
+// the reason of each violated constraint with the message and remediation URL
+// configured for it, if any.
+func denialReason(denial *cmpolicy.PolicyDenial, el field.ErrorList) string {
+	if denial == nil {
+		return el.ToAggregate().Error()
+	}
+
+	errs := make([]error, 0, len(el))
+	for _, err := range el {
+		message, remediationURL := denial.Message, denial.RemediationURL
+		if constraint := constraintDenial(denial.Constraints, err.Field); constraint != nil {
+			if len(constraint.Message) > 0 {
+				message = constraint.Message
+			}
+			if len(constraint.RemediationURL) > 0 {
+				remediationURL = constraint.RemediationURL
+			}
+		}
+
+		// Keep the field and value, so the requester can tell which value
+		// violated the constraint.
+		reason := *err
+		if len(message) > 0 {
+			reason.Detail = message
+		}
+		if len(remediationURL) > 0 {
+			reason.Detail = fmt.Sprintf("%s (see %s)", reason.Detail, remediationURL)
+		}
+
+		errs = append(errs, errors.New(reason.Error()))
+	}
+
+	// Aggregate removes duplicate reasons, so identical violations are only
+	// reported once.
+	return utilerrors.NewAggregate(errs).Error()
+}
+
+// validateDenial will add an error for each constraint denial whose path is
+// not the field path of the policy spec or one of its fields, so that a typo
+// in a path does not silently leave the constraint with the default reason.
+func validateDenial(el *field.ErrorList, path *field.Path, denial *cmpolicy.PolicyDenial) {
+	// Allow all
+	if denial == nil {
+		return
+	}
+
+	for i, constraint := range denial.Constraints {
+		if !isSpecFieldPath(constraint.Path) {
+			*el = append(*el, field.Invalid(path.Child("constraints").Index(i).Child("path"), constraint.Path, "not the path of a field of the policy spec"))
+		}
+	}
+}
+
+// specType is the type field paths of policies are resolved against.
+var specType = reflect.TypeOf(cmpolicy.CertificateRequestPolicySpec{})
+
+// isSpecFieldPath returns true if the path is "spec", or the path of a field
+// of the policy spec, such as "spec.allowedSubject.allowedOrganizations" or
+// "spec.rules[internal].allowedDNSNames". Fields are named as in JSON, and
+// elements of lists and maps are selected with brackets.
+func isSpecFieldPath(path string) bool {
+	if path != "spec" && !strings.HasPrefix(path, "spec.") && !strings.HasPrefix(path, "spec[") {
+		return false
+	}
+
+	t := specType
+	rest := path[len("spec"):]
+	for len(rest) > 0 {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			rest = rest[end+1:]
+
+			if t.Kind() != reflect.Struct {
+				return false
+			}
+			f, ok := jsonField(t, name)
+			if !ok {
+				return false
+			}
+			t = f.Type
+
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 || (t.Kind() != reflect.Slice && t.Kind() != reflect.Map) {
+				return false
+			}
+			rest = rest[end+1:]
+			t = t.Elem()
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// jsonField returns the field of the struct type with the given JSON name,
+// including the fields of inlined structs.
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if len(tag) > 1 && tag[0] == "" && tag[1] == "inline" {
+			if f, ok := jsonField(f.Type, name); ok {
+				return f, true
+			}
+			continue
+		}
+		if tag[0] == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// constraintDenial returns the constraint denial with the longest path which
+// matches the field path. Returns nil if none match.
+func constraintDenial(constraints []cmpolicy.PolicyConstraintDenial, fieldPath string) *cmpolicy.PolicyConstraintDenial {
+	var match *cmpolicy.PolicyConstraintDenial
+	for i := range constraints {
+		constraint := &constraints[i]
+		if !matchesFieldPath(constraint.Path, fieldPath) {
+			continue
+		}
+		if match == nil || len(constraint.Path) > len(match.Path) {
+			match = constraint
+		}
+	}
+	return match
+}
+
+// matchesFieldPath returns true if the field path is the path, or a field or
+// element of it.
+func matchesFieldPath(path, fieldPath string) bool {
+	if !strings.HasPrefix(fieldPath, path) {
+		return false
+	}
+	rest := fieldPath[len(path):]
+	return len(rest) == 0 || rest[0] == '.' || rest[0] == '['
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestIsSpecFieldPath(t *testing.T) {
+	tests := map[string]bool{
+		"spec":                                  true,
+		"spec.allowedDNSNames":                  true,
+		"spec.allowedPrivateKey.allowedMaxSize": true,
+		"spec.limits.maxDNSNames":               true,
+		"spec.rules[internal].allowedSubject.allowedOrganizations": true,
+		"spec.rules[0].dnsNames.requireBackingObjects":             true,
+		"spec.requiredAnnotations[team]":                           true,
+		"spec.alowedDNSNames":                                      false,
+		"spec.allowedDNSNames.value":                               false,
+		"spec.rules.allowedDNSNames":                               false,
+		"spec.rules[0":                                             false,
+		"spec.":                                                    false,
+		"status":                                                   false,
+		"specification":                                            false,
+	}
+
+	for path, exp := range tests {
+		t.Run(path, func(t *testing.T) {
+			if ok := isSpecFieldPath(path); ok != exp {
+				t.Errorf("unexpected result: exp=%t got=%t", exp, ok)
+			}
+		})
+	}
+}
+
+func TestDenialReason(t *testing.T) {
+	el := field.ErrorList{
+		field.Invalid(field.NewPath("spec", "allowedDNSNames"), "evil.com", "[*.example.com]"),
+		field.Invalid(field.NewPath("spec", "allowedIsCA"), true, "false"),
+	}
+
+	tests := map[string]struct {
+		denial      *cmpolicy.PolicyDenial
+		expContains []string
+	}{
+		"no denial: default reasons": {
+			denial:      nil,
+			expContains: []string{`spec.allowedDNSNames: Invalid value: "evil.com": [*.example.com]`},
+		},
+		"constraint message keeps the field and value": {
+			denial: &cmpolicy.PolicyDenial{
+				Message: "not allowed",
+				Constraints: []cmpolicy.PolicyConstraintDenial{
+					{Path: "spec.allowedDNSNames", Message: "use a DNS name under example.com", RemediationURL: "https://example.com/dns"},
+				},
+			},
+			expContains: []string{
+				`spec.allowedDNSNames: Invalid value: "evil.com": use a DNS name under example.com (see https://example.com/dns)`,
+				`spec.allowedIsCA: Invalid value: true: not allowed`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason := denialReason(test.denial, el)
+			for _, exp := range test.expContains {
+				if !strings.Contains(reason, exp) {
+					t.Errorf("expected reason to contain %q, got=%q", exp, reason)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateCertificateRequestDenialPaths(t *testing.T) {
+	allowedDNSNames := []string{"*.example.com"}
+	// The request has a duration, so that its issuer is not looked up.
+	cr := &cmapi.CertificateRequest{
+		Spec: cmapi.CertificateRequestSpec{
+			Duration: &metav1.Duration{Duration: time.Hour},
+			Request:  mustCSR(t, &x509.CertificateRequest{DNSNames: []string{"foo.example.com"}}),
+		},
+	}
+
+	tests := map[string]struct {
+		path    string
+		expErrs int
+	}{
+		"valid path": {path: "spec.allowedDNSNames", expErrs: 0},
+		"typo":       {path: "spec.alowedDNSNames", expErrs: 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := &cmpolicy.CertificateRequestPolicy{
+				Spec: cmpolicy.CertificateRequestPolicySpec{
+					PolicyConstraints: cmpolicy.PolicyConstraints{AllowedDNSNames: &allowedDNSNames},
+					Denial: &cmpolicy.PolicyDenial{
+						Constraints: []cmpolicy.PolicyConstraintDenial{{Path: test.path, Message: "denied"}},
+					},
+				},
+			}
+
+			var el field.ErrorList
+			if err := New(nil, Options{}).EvaluateCertificateRequest(context.TODO(), &el, policy, cr); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestEvaluatePrivateKeySize(t *testing.T) {
+	// mustParseCSR signs with a P-256 key.
+	csr := mustParseCSR(t, &x509.CertificateRequest{})
+
+	tests := map[string]struct {
+		policy   *cmpolicy.PolicyPrivateKey
+		expField string
+	}{
+		"below min size": {
+			policy:   &cmpolicy.PolicyPrivateKey{MinSize: intPtr(384)},
+			expField: "spec.allowedPrivateKey.allowedMinSize",
+		},
+		"above max size": {
+			policy:   &cmpolicy.PolicyPrivateKey{MaxSize: intPtr(128)},
+			expField: "spec.allowedPrivateKey.allowedMaxSize",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			if err := evaluatePrivateKey(&el, field.NewPath("spec", "allowedPrivateKey"), test.policy, csr); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != 1 || el[0].Field != test.expField {
+				t.Errorf("unexpected errors: exp=%s got=%v", test.expField, el)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+	"github.com/cert-manager/policy-approver/policy/checks"
+	"github.com/cert-manager/policy-approver/policy/checks/confusables"
+	"github.com/cert-manager/policy-approver/policy/checks/publicsuffix"
+)
+
+// reservedNames are special-use and reserved DNS names, as defined by RFC
+// 2606, RFC 6761, RFC 6762, RFC 7686 and RFC 8375, along with the ".internal"
+// TLD reserved by ICANN for private use. Names equal to, or under, any of
+// these are reserved.
+var reservedNames = []string{
+	"localhost",
+	"local",
+	"internal",
+	"test",
+	"invalid",
+	"example",
+	"example.com",
+	"example.net",
+	"example.org",
+	"onion",
+	"home.arpa",
+}
+
+// evaluateDNSNames will add errors for each requested DNS name that violates
+// the DNS name policy.
+func evaluateDNSNames(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyDNSNames, dnsNames []string) {
+	// Allow all
+	if policy == nil {
+		return
+	}
+
+	list := publicsuffix.Default()
+	for _, dnsName := range dnsNames {
+		name := normaliseDNSName(strings.TrimPrefix(dnsName, "*."))
+
+		if isTrue(policy.DenyPublicSuffixes) && list.IsPublicSuffix(name) {
+			*el = append(*el, field.Invalid(path.Child("denyPublicSuffixes"), dnsName, "DNS name is a public suffix"))
+		}
+
+		registrable, ok := list.RegistrableDomain(name)
+		if isTrue(policy.DenyUnderPublicSuffix) && ok && registrable == name {
+			*el = append(*el, field.Invalid(path.Child("denyUnderPublicSuffix"), dnsName, "DNS name is directly under a public suffix"))
+		}
+
+		if isTrue(policy.DenyReservedNames) && isReservedName(name) {
+			*el = append(*el, field.Invalid(path.Child("denyReservedNames"), dnsName, "DNS name is reserved for special use"))
+		}
+
+		if policy.AllowedRegistrableDomains != nil && !containsFold(*policy.AllowedRegistrableDomains, registrable) {
+			*el = append(*el, field.Invalid(path.Child("allowedRegistrableDomains"), dnsName, fmt.Sprintf("%v", *policy.AllowedRegistrableDomains)))
+		}
+
+		if isTrue(policy.RejectConfusableNames) {
+			evaluateConfusableName(el, path.Child("rejectConfusableNames"), policy.ProtectedDomains, dnsName, name)
+		}
+	}
+}
+
+// evaluateConfusableName will add errors if the DNS name, once converted from
+// punycode to Unicode, has a label which mixes scripts, or is confusable with
+// one of the protected domains or their subdomains.
+func evaluateConfusableName(el *field.ErrorList, path *field.Path, protectedDomains []string, dnsName, name string) {
+	unicodeName, err := idna.ToUnicode(name)
+	if err != nil {
+		*el = append(*el, field.Invalid(path, dnsName, fmt.Sprintf("invalid internationalised DNS name: %s", err)))
+		return
+	}
+
+	for _, label := range strings.Split(unicodeName, ".") {
+		if confusables.IsMixedScript(label) {
+			*el = append(*el, field.Invalid(path, dnsName, fmt.Sprintf("label %q mixes scripts", label)))
+			break
+		}
+	}
+
+	skeleton := confusables.FoldedSkeleton(unicodeName)
+	for _, protected := range protectedDomains {
+		protected = strings.ToLower(strings.TrimSuffix(protected, "."))
+
+		// The protected domain itself and its subdomains are not confusable.
+		if unicodeName == protected || strings.HasSuffix(unicodeName, "."+protected) {
+			continue
+		}
+
+		protectedSkeleton := confusables.FoldedSkeleton(protected)
+		if skeleton == protectedSkeleton || strings.HasSuffix(skeleton, "."+protectedSkeleton) {
+			*el = append(*el, field.Invalid(path, dnsName, fmt.Sprintf("%q is confusable with protected domain %q", unicodeName, protected)))
+		}
+	}
+}
+
+// isReservedName returns true if the name is equal to, or under, a reserved
+// name.
+func isReservedName(name string) bool {
+	for _, reserved := range reservedNames {
+		if name == reserved || strings.HasSuffix(name, "."+reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold returns true if the slice contains s, ignoring case. An empty s
+// is never contained.
+func containsFold(slice []string, s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, e := range slice {
+		if strings.EqualFold(strings.TrimSuffix(e, "."), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// normaliseDNSName lower cases the DNS name and removes any trailing dot.
+func normaliseDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// isTrue returns true if b is not nil and true.
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+// hasEmptyLabel returns true if any of the given DNS labels are empty.
+func hasEmptyLabel(labels []string) bool {
+	for _, label := range labels {
+		if len(label) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateWildcardCertificates will add errors for each requested wildcard DNS
+// name that violates the wildcard certificate policy.
+func evaluateWildcardCertificates(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyWildcardCertificates, dnsNames []string, duration *metav1.Duration) {
+	// Allow all
+	if policy == nil {
+		return
+	}
+
+	var wildcards []string
+	for _, dnsName := range dnsNames {
+		if strings.Contains(dnsName, "*") {
+			wildcards = append(wildcards, dnsName)
+		}
+	}
+
+	if len(wildcards) == 0 {
+		return
+	}
+
+	if policy.Allowed != nil && !*policy.Allowed {
+		*el = append(*el, field.Invalid(path.Child("allowed"), wildcards, "wildcard DNS names are not allowed"))
+		return
+	}
+
+	for _, wildcard := range wildcards {
+		// Only a wildcard which is the entire left-most label is accepted by
+		// verifiers, as per RFC 6125 section 6.4.3.
+		if !strings.HasPrefix(wildcard, "*.") || strings.Contains(wildcard[2:], "*") {
+			*el = append(*el, field.Invalid(path, wildcard, "wildcard must be the entire left-most label"))
+			continue
+		}
+
+		parent := normaliseDNSName(wildcard[2:])
+		labels := strings.Split(parent, ".")
+		if hasEmptyLabel(labels) {
+			*el = append(*el, field.Invalid(path, wildcard, "wildcard parent must not contain empty labels"))
+			continue
+		}
+
+		if policy.MinLabels != nil {
+			if len(labels) < *policy.MinLabels {
+				*el = append(*el, field.Invalid(path.Child("minLabels"), wildcard, fmt.Sprintf("%d", *policy.MinLabels)))
+			}
+		}
+
+		if isTrue(policy.DenyUnderPublicSuffix) && publicsuffix.Default().IsPublicSuffix(parent) {
+			*el = append(*el, field.Invalid(path.Child("denyUnderPublicSuffix"), wildcard, "wildcard is directly under a public suffix"))
+		}
+	}
+
+	checks.MaxDuration(el, path.Child("maxDuration"), policy.MaxDuration, duration)
+}
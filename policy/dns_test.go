@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestEvaluateWildcardCertificates(t *testing.T) {
+	yes := true
+	policy := &cmpolicy.PolicyWildcardCertificates{
+		MinLabels:             intPtr(2),
+		DenyUnderPublicSuffix: &yes,
+	}
+
+	tests := map[string]struct {
+		dnsNames []string
+		expErrs  int
+	}{
+		"no wildcards should allow": {
+			dnsNames: []string{"example.com"},
+			expErrs:  0,
+		},
+		"wildcard with enough labels should allow": {
+			dnsNames: []string{"*.example.com"},
+			expErrs:  0,
+		},
+		"wildcard with enough labels and a trailing dot should allow": {
+			dnsNames: []string{"*.example.com."},
+			expErrs:  0,
+		},
+		"wildcard with a single label should deny": {
+			dnsNames: []string{"*.example"},
+			expErrs:  2,
+		},
+		"wildcard with a single label and a trailing dot should deny": {
+			dnsNames: []string{"*.example."},
+			expErrs:  2,
+		},
+		"wildcard directly under a TLD with a trailing dot should deny": {
+			dnsNames: []string{"*.com."},
+			expErrs:  2,
+		},
+		"wildcard directly under a mixed case public suffix should deny": {
+			dnsNames: []string{"*.Co.UK"},
+			expErrs:  1,
+		},
+		"wildcard directly under a public suffix with a trailing dot should deny": {
+			dnsNames: []string{"*.github.io."},
+			expErrs:  1,
+		},
+		"wildcard under a registered domain of a public suffix should allow": {
+			dnsNames: []string{"*.Example.Co.UK."},
+			expErrs:  0,
+		},
+		"wildcard with an empty label should deny": {
+			dnsNames: []string{"*.a..b"},
+			expErrs:  1,
+		},
+		"wildcard with an empty parent should deny": {
+			dnsNames: []string{"*."},
+			expErrs:  1,
+		},
+		"wildcard which is not the entire left-most label should deny": {
+			dnsNames: []string{"foo*.example.com"},
+			expErrs:  1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			evaluateWildcardCertificates(&el, field.NewPath("spec"), policy, test.dnsNames, nil)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
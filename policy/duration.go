@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IssuerDefaultDurationAnnotationKey is the annotation on a ClusterIssuer
+// overriding the duration it issues certificates with when a request does not
+// specify one, for issuers whose default differs from cert-manager's. The
+// annotation is ignored on namespaced Issuers, since it would allow anyone who
+// may edit an Issuer to choose the duration their requests are evaluated
+// against.
+const IssuerDefaultDurationAnnotationKey = "policy.cert-manager.io/default-duration"
+
+// effectiveDuration returns the duration the request will be issued with. If
+// the request does not specify a duration, this is the default duration of
+// its issuer, else the configured default duration, else cert-manager's
+// default duration.
+func (p *Policy) effectiveDuration(ctx context.Context, cr *cmapi.CertificateRequest) (*metav1.Duration, error) {
+	if cr.Spec.Duration != nil {
+		return cr.Spec.Duration, nil
+	}
+
+	issuer, err := p.getIssuer(ctx, cr.Spec.IssuerRef, cr.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if issuer, ok := issuer.(*cmapi.ClusterIssuer); ok {
+		if value, ok := issuer.Annotations[IssuerDefaultDurationAnnotationKey]; ok {
+			if d, err := time.ParseDuration(value); err == nil && d > 0 {
+				return &metav1.Duration{Duration: d}, nil
+			}
+		}
+	}
+
+	if p.opts.DefaultDuration > 0 {
+		return &metav1.Duration{Duration: p.opts.DefaultDuration}, nil
+	}
+
+	return &metav1.Duration{Duration: cmapi.DefaultCertificateDuration}, nil
+}
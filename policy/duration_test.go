@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEffectiveDuration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := cmapi.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := map[string]string{IssuerDefaultDurationAnnotationKey: "1h"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "annotated", Annotations: annotations}},
+		&cmapi.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "annotated", Annotations: annotations}},
+		&cmapi.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "plain"}},
+	).Build()
+
+	tests := map[string]struct {
+		duration    *metav1.Duration
+		issuerRef   cmmeta.ObjectReference
+		expDuration time.Duration
+	}{
+		"explicit duration": {
+			duration:    &metav1.Duration{Duration: 2 * time.Hour},
+			issuerRef:   cmmeta.ObjectReference{Name: "annotated", Kind: cmapi.ClusterIssuerKind},
+			expDuration: 2 * time.Hour,
+		},
+		"annotated ClusterIssuer": {
+			issuerRef:   cmmeta.ObjectReference{Name: "annotated", Kind: cmapi.ClusterIssuerKind},
+			expDuration: time.Hour,
+		},
+		"ClusterIssuer without annotation: configured default": {
+			issuerRef:   cmmeta.ObjectReference{Name: "plain", Kind: cmapi.ClusterIssuerKind},
+			expDuration: 24 * time.Hour,
+		},
+		"annotated namespaced Issuer: annotation ignored": {
+			issuerRef:   cmmeta.ObjectReference{Name: "annotated", Kind: cmapi.IssuerKind},
+			expDuration: 24 * time.Hour,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test"},
+				Spec:       cmapi.CertificateRequestSpec{Duration: test.duration, IssuerRef: test.issuerRef},
+			}
+
+			duration, err := New(c, Options{DefaultDuration: 24 * time.Hour}).effectiveDuration(context.TODO(), cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if duration.Duration != test.expDuration {
+				t.Errorf("unexpected duration: exp=%s got=%s", test.expDuration, duration.Duration)
+			}
+		})
+	}
+}
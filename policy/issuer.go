@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+	"github.com/cert-manager/policy-approver/policy/checks"
+)
+
+// evaluateIssuer will add an error if the issuer referenced by the request
+// does not match any of the allowed issuers.
+func (p *Policy) evaluateIssuer(ctx context.Context, el *field.ErrorList, path *field.Path, policy *[]cmpolicy.PolicyIssuer, cr *cmapi.CertificateRequest) error {
+	// Allow all
+	if policy == nil {
+		return nil
+	}
+
+	ref := checks.NormaliseIssuerRef(cr.Spec.IssuerRef)
+
+	// Only fetch the issuer's labels if a selector needs them
+	var issuerLabels labels.Set
+	for _, issuer := range *policy {
+		if !checks.IssuerRef(issuer, ref) {
+			continue
+		}
+
+		if issuer.Selector == nil {
+			return nil
+		}
+
+		if issuerLabels == nil {
+			var err error
+			issuerLabels, err = p.issuerLabels(ctx, ref, cr.Namespace)
+			if err != nil {
+				return err
+			}
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(issuer.Selector)
+		if err != nil {
+			*el = append(*el, field.Invalid(path, issuer.Selector, fmt.Sprintf("invalid selector: %s", err)))
+			continue
+		}
+		if selector.Matches(issuerLabels) {
+			return nil
+		}
+	}
+
+	*el = append(*el, field.Invalid(path, ref, fmt.Sprintf("%v", *policy)))
+	return nil
+}
+
+// issuerMatches returns true if the issuer referenced by the request matches
+// the given issuer. Invalid selectors match no issuer, and add an error.
+func (p *Policy) issuerMatches(ctx context.Context, el *field.ErrorList, path *field.Path, issuer cmpolicy.PolicyIssuer, cr *cmapi.CertificateRequest) (bool, error) {
+	ref := checks.NormaliseIssuerRef(cr.Spec.IssuerRef)
+	if !checks.IssuerRef(issuer, ref) {
+		return false, nil
+	}
+	if issuer.Selector == nil {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(issuer.Selector)
+	if err != nil {
+		*el = append(*el, field.Invalid(path.Child("selector"), issuer.Selector, fmt.Sprintf("invalid selector: %s", err)))
+		return false, nil
+	}
+	issuerLabels, err := p.issuerLabels(ctx, ref, cr.Namespace)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(issuerLabels), nil
+}
+
+// issuerLabels returns the labels of the referenced Issuer or ClusterIssuer.
+// Issuers of other groups and kinds, and issuers which do not exist, have no
+// labels.
+func (p *Policy) issuerLabels(ctx context.Context, ref cmmeta.ObjectReference, namespace string) (labels.Set, error) {
+	issuer, err := p.getIssuer(ctx, ref, namespace)
+	if err != nil || issuer == nil {
+		return labels.Set{}, err
+	}
+	return labels.Set(issuer.GetObjectMeta().Labels), nil
+}
+
+// getIssuer returns the referenced Issuer or ClusterIssuer. Issuers are
+// resolved in the given namespace. Returns nil if the reference is not to an
+// Issuer or ClusterIssuer, or it does not exist.
+func (p *Policy) getIssuer(ctx context.Context, ref cmmeta.ObjectReference, namespace string) (cmapi.GenericIssuer, error) {
+	ref = checks.NormaliseIssuerRef(ref)
+	if ref.Group != cmapi.SchemeGroupVersion.Group {
+		return nil, nil
+	}
+
+	var issuer cmapi.GenericIssuer
+	key := client.ObjectKey{Name: ref.Name}
+	switch ref.Kind {
+	case cmapi.IssuerKind:
+		issuer = new(cmapi.Issuer)
+		key.Namespace = namespace
+	case cmapi.ClusterIssuerKind:
+		issuer = new(cmapi.ClusterIssuer)
+	default:
+		return nil, nil
+	}
+
+	if err := p.Get(ctx, key, issuer); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return issuer, nil
+}
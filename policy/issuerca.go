@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+	"github.com/cert-manager/policy-approver/policy/checks"
+)
+
+// evaluateIssuerCAExpiry will add an error if the request would be valid for
+// longer than the CA certificate of its issuer, less the safety margin.
+func (p *Policy) evaluateIssuerCAExpiry(ctx context.Context, el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyIssuerCAExpiry, cr *cmapi.CertificateRequest, duration *metav1.Duration) error {
+	// Allow all
+	if policy == nil {
+		return nil
+	}
+
+	ca, err := p.issuerCACertificate(ctx, cr)
+	if err != nil || ca == nil {
+		return err
+	}
+
+	notAfter := ca.NotAfter
+	if policy.SafetyMargin != nil {
+		notAfter = notAfter.Add(-policy.SafetyMargin.Duration)
+	}
+
+	if expiry := time.Now().Add(duration.Duration); expiry.After(notAfter) {
+		*el = append(*el, field.Invalid(path, duration,
+			fmt.Sprintf("request would expire at %s, after the issuer CA certificate is valid until %s",
+				expiry.UTC().Format(time.RFC3339), notAfter.UTC().Format(time.RFC3339))))
+	}
+
+	return nil
+}
+
+// evaluateIssuerNameConstraints will add an error for each requested name
+// which the name constraints of the CA certificate of its issuer do not allow.
+func (p *Policy) evaluateIssuerNameConstraints(ctx context.Context, el *field.ErrorList, path *field.Path, enforce *bool, cr *cmapi.CertificateRequest, csr *x509.CertificateRequest) error {
+	// Allow all
+	if enforce == nil || !*enforce {
+		return nil
+	}
+
+	ca, err := p.issuerCACertificate(ctx, cr)
+	if err != nil || ca == nil {
+		return err
+	}
+
+	checks.NameConstraints(el, path, ca, csr)
+
+	return nil
+}
+
+// issuerCACertificate returns the CA certificate of the CA issuer referenced
+// by the request. Returns nil if the issuer is not a CA issuer, or the issuer
+// or its Secret do not exist.
+func (p *Policy) issuerCACertificate(ctx context.Context, cr *cmapi.CertificateRequest) (*x509.Certificate, error) {
+	issuer, err := p.getIssuer(ctx, cr.Spec.IssuerRef, cr.Namespace)
+	if err != nil || issuer == nil || issuer.GetSpec().CA == nil {
+		return nil, err
+	}
+
+	// The Secret of a ClusterIssuer is in the cluster resource namespace
+	namespace := cr.Namespace
+	if _, ok := issuer.(*cmapi.ClusterIssuer); ok {
+		namespace = p.opts.ClusterResourceNamespace
+	}
+
+	reader := p.opts.SecretReader
+	if reader == nil {
+		reader = p.Client
+	}
+
+	secret := new(corev1.Secret)
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: issuer.GetSpec().CA.SecretName}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ca, err := utilpki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CA certificate of issuer %q: %w", issuer.GetName(), err)
+	}
+
+	return ca, nil
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func mustCACertificate(t *testing.T, template *x509.Certificate) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template.SerialNumber = big.NewInt(1)
+	template.Subject = pkix.Name{CommonName: "ca"}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func issuerCAScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, cmapi.AddToScheme, cmpolicy.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return scheme
+}
+
+func caIssuer(namespace, name, secretName string) *cmapi.Issuer {
+	return &cmapi.Issuer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: cmapi.IssuerSpec{
+			IssuerConfig: cmapi.IssuerConfig{CA: &cmapi.CAIssuer{SecretName: secretName}},
+		},
+	}
+}
+
+func caSecret(namespace, name string, cert []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string][]byte{corev1.TLSCertKey: cert},
+	}
+}
+
+func TestEvaluateIssuerCAExpiry(t *testing.T) {
+	ca := mustCACertificate(t, &x509.Certificate{NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(48 * time.Hour)})
+	c := fake.NewClientBuilder().WithScheme(issuerCAScheme(t)).WithObjects(
+		caIssuer("test", "ca", "ca"),
+		caSecret("test", "ca", ca),
+		caIssuer("test", "missing-secret", "missing"),
+		&cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "self-signed"}, Spec: cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{SelfSigned: &cmapi.SelfSignedIssuer{}}}},
+		&cmapi.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "ca"}, Spec: cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{CA: &cmapi.CAIssuer{SecretName: "ca"}}}},
+		caSecret("cert-manager", "ca", ca),
+	).Build()
+
+	tests := map[string]struct {
+		policy    *cmpolicy.PolicyIssuerCAExpiry
+		issuerRef cmmeta.ObjectReference
+		duration  time.Duration
+		expErrs   int
+	}{
+		"no policy should allow": {
+			policy:    nil,
+			issuerRef: cmmeta.ObjectReference{Name: "ca", Kind: cmapi.IssuerKind},
+			duration:  72 * time.Hour,
+			expErrs:   0,
+		},
+		"CA expiring after the duration should allow": {
+			policy:    new(cmpolicy.PolicyIssuerCAExpiry),
+			issuerRef: cmmeta.ObjectReference{Name: "ca", Kind: cmapi.IssuerKind},
+			duration:  24 * time.Hour,
+			expErrs:   0,
+		},
+		"CA expiring before the duration should deny": {
+			policy:    new(cmpolicy.PolicyIssuerCAExpiry),
+			issuerRef: cmmeta.ObjectReference{Name: "ca", Kind: cmapi.IssuerKind},
+			duration:  72 * time.Hour,
+			expErrs:   1,
+		},
+		"CA expiring within the safety margin of the duration should deny": {
+			policy:    &cmpolicy.PolicyIssuerCAExpiry{SafetyMargin: &metav1.Duration{Duration: 36 * time.Hour}},
+			issuerRef: cmmeta.ObjectReference{Name: "ca", Kind: cmapi.IssuerKind},
+			duration:  24 * time.Hour,
+			expErrs:   1,
+		},
+		"ClusterIssuer CA expiring before the duration should deny": {
+			policy:    new(cmpolicy.PolicyIssuerCAExpiry),
+			issuerRef: cmmeta.ObjectReference{Name: "ca", Kind: cmapi.ClusterIssuerKind},
+			duration:  72 * time.Hour,
+			expErrs:   1,
+		},
+		"missing CA Secret should allow": {
+			policy:    new(cmpolicy.PolicyIssuerCAExpiry),
+			issuerRef: cmmeta.ObjectReference{Name: "missing-secret", Kind: cmapi.IssuerKind},
+			duration:  72 * time.Hour,
+			expErrs:   0,
+		},
+		"issuer which is not a CA issuer should allow": {
+			policy:    new(cmpolicy.PolicyIssuerCAExpiry),
+			issuerRef: cmmeta.ObjectReference{Name: "self-signed", Kind: cmapi.IssuerKind},
+			duration:  72 * time.Hour,
+			expErrs:   0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test"},
+				Spec:       cmapi.CertificateRequestSpec{IssuerRef: test.issuerRef},
+			}
+
+			var el field.ErrorList
+			p := New(c, Options{ClusterResourceNamespace: "cert-manager"})
+			if err := p.evaluateIssuerCAExpiry(context.TODO(), &el, field.NewPath("spec", "issuerCAExpiry"), test.policy, cr, &metav1.Duration{Duration: test.duration}); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestEvaluateIssuerNameConstraints(t *testing.T) {
+	ca := mustCACertificate(t, &x509.Certificate{
+		NotBefore:           time.Now().Add(-time.Hour),
+		NotAfter:            time.Now().Add(48 * time.Hour),
+		PermittedDNSDomains: []string{"example.com"},
+		ExcludedDNSDomains:  []string{"admin.example.com"},
+	})
+
+	yes := true
+	crp := &cmpolicy.CertificateRequestPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "name-constraints"},
+		Spec: cmpolicy.CertificateRequestPolicySpec{
+			PolicyConstraints: cmpolicy.PolicyConstraints{
+				AllowedDNSNames:              &[]string{"*"},
+				EnforceIssuerNameConstraints: &yes,
+			},
+		},
+	}
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+		crp,
+		caIssuer("test", "ca", "ca"),
+		caSecret("test", "ca", ca),
+		&cmapi.Issuer{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "self-signed"}, Spec: cmapi.IssuerSpec{IssuerConfig: cmapi.IssuerConfig{SelfSigned: &cmapi.SelfSignedIssuer{}}}},
+	}
+	c := sarClient{Client: fake.NewClientBuilder().WithScheme(issuerCAScheme(t)).WithRuntimeObjects(objects...).Build(), allowed: true}
+
+	tests := map[string]struct {
+		issuer      string
+		dnsNames    []string
+		expApproved bool
+	}{
+		"names permitted by the CA should approve": {
+			issuer:      "ca",
+			dnsNames:    []string{"example.com", "www.example.com"},
+			expApproved: true,
+		},
+		"name outside the permitted domains of the CA should deny": {
+			issuer:      "ca",
+			dnsNames:    []string{"www.example.com", "www.acme.com"},
+			expApproved: false,
+		},
+		"name in the excluded domains of the CA should deny": {
+			issuer:      "ca",
+			dnsNames:    []string{"admin.example.com"},
+			expApproved: false,
+		},
+		"issuer which is not a CA issuer should approve": {
+			issuer:      "self-signed",
+			dnsNames:    []string{"www.acme.com"},
+			expApproved: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test"},
+				Spec: cmapi.CertificateRequestSpec{
+					Username:  "alice",
+					IssuerRef: cmmeta.ObjectReference{Name: test.issuer, Kind: cmapi.IssuerKind},
+					Duration:  &metav1.Duration{Duration: time.Hour},
+					Request:   mustCSR(t, &x509.CertificateRequest{DNSNames: test.dnsNames}),
+				},
+			}
+
+			approved, reason, err := New(c, Options{}).Evaluate(context.TODO(), cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if approved != test.expApproved {
+				t.Errorf("unexpected result: exp=%t got=%t reason=%q", test.expApproved, approved, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// evaluateRequiredKeyValues will add an error for each pattern which is not
+// matched by any of the key value pairs.
+func evaluateRequiredKeyValues(el *field.ErrorList, path *field.Path, required *[]cmpolicy.PolicyKeyValuePattern, kvs map[string]string) {
+	// Allow all
+	if required == nil {
+		return
+	}
+
+	for _, pattern := range *required {
+		found := false
+		for k, v := range kvs {
+			if matchesKeyValue([]cmpolicy.PolicyKeyValuePattern{pattern}, k, v) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			value := pattern.Key
+			if pattern.Value != nil {
+				value += "=" + *pattern.Value
+			}
+			*el = append(*el, field.Required(path.Key(value), "missing from request"))
+		}
+	}
+}
+
+// evaluateAllowedKeyValues will add an error for each key value pair which is
+// not matched by any of the patterns.
+func evaluateAllowedKeyValues(el *field.ErrorList, path *field.Path, allowed *[]cmpolicy.PolicyKeyValuePattern, kvs map[string]string) {
+	// Allow all
+	if allowed == nil {
+		return
+	}
+
+	evaluateKeyValues(el, path, *allowed, kvs)
+}
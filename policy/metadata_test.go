@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestEvaluateRequiredKeyValues(t *testing.T) {
+	team := "team-*"
+
+	tests := map[string]struct {
+		required *[]cmpolicy.PolicyKeyValuePattern
+		kvs      map[string]string
+		expErrs  int
+	}{
+		"no required key values should allow": {
+			required: nil,
+			kvs:      nil,
+			expErrs:  0,
+		},
+		"required key present should allow": {
+			required: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}},
+			kvs:      map[string]string{"team": "a", "app": "web"},
+			expErrs:  0,
+		},
+		"required key missing should deny": {
+			required: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}},
+			kvs:      map[string]string{"app": "web"},
+			expErrs:  1,
+		},
+		"required key with a matching value should allow": {
+			required: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team", Value: &team}},
+			kvs:      map[string]string{"team": "team-a"},
+			expErrs:  0,
+		},
+		"required key with a value which does not match should deny": {
+			required: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team", Value: &team}},
+			kvs:      map[string]string{"team": "other"},
+			expErrs:  1,
+		},
+		"wildcard key matched by any key should allow": {
+			required: &[]cmpolicy.PolicyKeyValuePattern{{Key: "example.com/*"}},
+			kvs:      map[string]string{"example.com/owner": "a"},
+			expErrs:  0,
+		},
+		"each missing pattern should deny": {
+			required: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}, {Key: "app"}, {Key: "env"}},
+			kvs:      map[string]string{"app": "web"},
+			expErrs:  2,
+		},
+		"required key values with none present should deny": {
+			required: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}},
+			kvs:      nil,
+			expErrs:  1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			evaluateRequiredKeyValues(&el, field.NewPath("spec", "requiredLabels"), test.required, test.kvs)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestEvaluateAllowedKeyValues(t *testing.T) {
+	team := "team-*"
+
+	tests := map[string]struct {
+		allowed *[]cmpolicy.PolicyKeyValuePattern
+		kvs     map[string]string
+		expErrs int
+	}{
+		"no allowed key values should allow": {
+			allowed: nil,
+			kvs:     map[string]string{"team": "a"},
+			expErrs: 0,
+		},
+		"no key values should allow": {
+			allowed: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}},
+			kvs:     nil,
+			expErrs: 0,
+		},
+		"every key value allowed should allow": {
+			allowed: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team", Value: &team}, {Key: "example.com/*"}},
+			kvs:     map[string]string{"team": "team-a", "example.com/owner": "a"},
+			expErrs: 0,
+		},
+		"key which is not allowed should deny": {
+			allowed: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}},
+			kvs:     map[string]string{"team": "a", "app": "web"},
+			expErrs: 1,
+		},
+		"value which is not allowed should deny": {
+			allowed: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team", Value: &team}},
+			kvs:     map[string]string{"team": "other"},
+			expErrs: 1,
+		},
+		"empty allowed list should deny every key value": {
+			allowed: &[]cmpolicy.PolicyKeyValuePattern{},
+			kvs:     map[string]string{"team": "a", "app": "web"},
+			expErrs: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			evaluateAllowedKeyValues(&el, field.NewPath("spec", "allowedAnnotations"), test.allowed, test.kvs)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestEvaluateConstraintsMetadata(t *testing.T) {
+	owner := "example.com/owner"
+	csr := mustParseCSR(t, &x509.CertificateRequest{})
+
+	tests := map[string]struct {
+		constraints cmpolicy.PolicyConstraints
+		labels      map[string]string
+		annotations map[string]string
+		expFields   []string
+	}{
+		"required label present should allow": {
+			constraints: cmpolicy.PolicyConstraints{RequiredLabels: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}}},
+			labels:      map[string]string{"team": "a"},
+		},
+		"required label only present as an annotation should deny": {
+			constraints: cmpolicy.PolicyConstraints{RequiredLabels: &[]cmpolicy.PolicyKeyValuePattern{{Key: "team"}}},
+			annotations: map[string]string{"team": "a"},
+			expFields:   []string{"spec.requiredLabels[team]"},
+		},
+		"required annotation present should allow": {
+			constraints: cmpolicy.PolicyConstraints{RequiredAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: owner}}},
+			annotations: map[string]string{owner: "a"},
+		},
+		"required annotation only present as a label should deny": {
+			constraints: cmpolicy.PolicyConstraints{RequiredAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: owner}}},
+			labels:      map[string]string{owner: "a"},
+			expFields:   []string{"spec.requiredAnnotations[" + owner + "]"},
+		},
+		"annotation which is not allowed should deny": {
+			constraints: cmpolicy.PolicyConstraints{AllowedAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: owner}}},
+			annotations: map[string]string{owner: "a", "other": "b"},
+			expFields:   []string{"spec.allowedAnnotations"},
+		},
+		"allowed annotations do not constrain labels": {
+			constraints: cmpolicy.PolicyConstraints{AllowedAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: owner}}},
+			labels:      map[string]string{"other": "b"},
+		},
+		"required annotation which is also allowed should allow": {
+			constraints: cmpolicy.PolicyConstraints{
+				RequiredAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: owner}},
+				AllowedAnnotations:  &[]cmpolicy.PolicyKeyValuePattern{{Key: "example.com/*"}},
+			},
+			annotations: map[string]string{owner: "a"},
+		},
+		"required annotation which is not allowed should deny": {
+			constraints: cmpolicy.PolicyConstraints{
+				RequiredAnnotations: &[]cmpolicy.PolicyKeyValuePattern{{Key: owner}},
+				AllowedAnnotations:  &[]cmpolicy.PolicyKeyValuePattern{{Key: "other.com/*"}},
+			},
+			annotations: map[string]string{owner: "a"},
+			expFields:   []string{"spec.allowedAnnotations"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Labels: test.labels, Annotations: test.annotations},
+			}
+
+			var el field.ErrorList
+			p := New(nil, Options{})
+			if err := p.evaluateConstraints(context.TODO(), &el, field.NewPath("spec"), &test.constraints, cr, csr, &metav1.Duration{Duration: time.Hour}); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != len(test.expFields) {
+				t.Fatalf("unexpected errors: exp=%v got=%v", test.expFields, el)
+			}
+			for i, err := range el {
+				if err.Field != test.expFields[i] {
+					t.Errorf("unexpected field: exp=%q got=%q", test.expFields[i], err.Field)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+const (
+	nodeUsernamePrefix = "system:node:"
+	nodesGroup         = "system:nodes"
+)
+
+// nodeUsages are the usages a kubelet serving certificate may be requested
+// with. Server auth is required.
+var nodeUsages = []cmapi.KeyUsage{
+	cmapi.UsageDigitalSignature,
+	cmapi.UsageKeyEncipherment,
+	cmapi.UsageServerAuth,
+}
+
+// evaluateNodeIdentity will add an error if the request was not made by a
+// kubelet, or if the request does not identify that kubelet's Node.
+func (p *Policy) evaluateNodeIdentity(ctx context.Context, el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyNodeIdentity, cr *cmapi.CertificateRequest, csr *x509.CertificateRequest) error {
+	// Allow all
+	if policy == nil {
+		return nil
+	}
+
+	nodeName := strings.TrimPrefix(cr.Spec.Username, nodeUsernamePrefix)
+	if !strings.HasPrefix(cr.Spec.Username, nodeUsernamePrefix) || len(nodeName) == 0 {
+		*el = append(*el, field.Invalid(path, cr.Spec.Username, "requester is not a Node"))
+		return nil
+	}
+	if !containsString(cr.Spec.Groups, nodesGroup) {
+		*el = append(*el, field.Invalid(path, cr.Spec.Groups, fmt.Sprintf("requester is not in the %q group", nodesGroup)))
+		return nil
+	}
+
+	if csr.Subject.CommonName != cr.Spec.Username {
+		*el = append(*el, field.Invalid(path.Child("commonName"), csr.Subject.CommonName, fmt.Sprintf("must be %q", cr.Spec.Username)))
+	}
+	if len(csr.Subject.Organization) != 1 || csr.Subject.Organization[0] != nodesGroup {
+		*el = append(*el, field.Invalid(path.Child("organizations"), csr.Subject.Organization, fmt.Sprintf("must be [%q]", nodesGroup)))
+	}
+	for _, uri := range csr.URIs {
+		*el = append(*el, field.Invalid(path.Child("uris"), uri.String(), "Node certificates may not contain URIs"))
+	}
+	for _, email := range csr.EmailAddresses {
+		*el = append(*el, field.Invalid(path.Child("emailAddresses"), email, "Node certificates may not contain email addresses"))
+	}
+	if len(csr.DNSNames) == 0 && len(csr.IPAddresses) == 0 {
+		*el = append(*el, field.Invalid(path, csr.Subject.CommonName, "Node certificates must contain at least one DNS name or IP address"))
+	}
+
+	for _, usage := range cr.Spec.Usages {
+		if !containsUsage(nodeUsages, usage) {
+			*el = append(*el, field.Invalid(path.Child("usages"), usage, fmt.Sprintf("Node certificates may only be requested with %v", nodeUsages)))
+		}
+	}
+	if !containsUsage(cr.Spec.Usages, cmapi.UsageServerAuth) {
+		*el = append(*el, field.Invalid(path.Child("usages"), cr.Spec.Usages, fmt.Sprintf("Node certificates must be requested with %q", cmapi.UsageServerAuth)))
+	}
+	if cr.Spec.IsCA {
+		*el = append(*el, field.Invalid(path.Child("isCA"), cr.Spec.IsCA, "Node certificates may not be requested for a CA"))
+	}
+
+	node := new(corev1.Node)
+	if err := p.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			*el = append(*el, field.Invalid(path, nodeName, "requesting Node does not exist"))
+			return nil
+		}
+		return err
+	}
+
+	nodeIPs := make(map[string]bool)
+	nodeDNSNames := make(map[string]bool)
+	for _, address := range node.Status.Addresses {
+		if policy.AllowedAddressTypes != nil && !containsAddressType(*policy.AllowedAddressTypes, address.Type) {
+			continue
+		}
+		switch address.Type {
+		case corev1.NodeInternalIP, corev1.NodeExternalIP:
+			if ip := net.ParseIP(address.Address); ip != nil {
+				nodeIPs[ip.String()] = true
+			}
+		case corev1.NodeHostName, corev1.NodeInternalDNS, corev1.NodeExternalDNS:
+			nodeDNSNames[normaliseDNSName(address.Address)] = true
+		}
+	}
+
+	for _, ip := range csr.IPAddresses {
+		if !nodeIPs[ip.String()] {
+			*el = append(*el, field.Invalid(path.Child("ipAddresses"), ip.String(), "not an address of the requesting Node"))
+		}
+	}
+	for _, dnsName := range csr.DNSNames {
+		if !nodeDNSNames[normaliseDNSName(dnsName)] {
+			*el = append(*el, field.Invalid(path.Child("dnsNames"), dnsName, "not an address of the requesting Node"))
+		}
+	}
+
+	return nil
+}
+
+// containsAddressType returns true if the address type is in the list.
+func containsAddressType(types []corev1.NodeAddressType, addressType corev1.NodeAddressType) bool {
+	for _, t := range types {
+		if t == addressType {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString returns true if the string is in the list.
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestEvaluateNodeIdentity(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeHostName, Address: "node-1"},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(node).Build()
+
+	subject := pkix.Name{CommonName: "system:node:node-1", Organization: []string{nodesGroup}}
+	servingUsages := []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth}
+
+	tests := map[string]struct {
+		usages  []cmapi.KeyUsage
+		isCA    bool
+		csr     *x509.CertificateRequest
+		expErrs int
+	}{
+		"serving certificate for the Node's addresses": {
+			usages:  servingUsages,
+			csr:     &x509.CertificateRequest{Subject: subject, DNSNames: []string{"node-1"}, IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}},
+			expErrs: 0,
+		},
+		"address which is not the Node's": {
+			usages:  servingUsages,
+			csr:     &x509.CertificateRequest{Subject: subject, DNSNames: []string{"node-2"}},
+			expErrs: 1,
+		},
+		"no DNS names or IP addresses": {
+			usages:  servingUsages,
+			csr:     &x509.CertificateRequest{Subject: subject},
+			expErrs: 1,
+		},
+		"CA": {
+			usages:  servingUsages,
+			isCA:    true,
+			csr:     &x509.CertificateRequest{Subject: subject, DNSNames: []string{"node-1"}},
+			expErrs: 1,
+		},
+		"no usages, so not server auth": {
+			usages:  nil,
+			csr:     &x509.CertificateRequest{Subject: subject, DNSNames: []string{"node-1"}},
+			expErrs: 1,
+		},
+		"client auth usage": {
+			usages:  append([]cmapi.KeyUsage{cmapi.UsageClientAuth}, servingUsages...),
+			csr:     &x509.CertificateRequest{Subject: subject, DNSNames: []string{"node-1"}},
+			expErrs: 1,
+		},
+		"cert sign usage without server auth": {
+			usages:  []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageCertSign},
+			csr:     &x509.CertificateRequest{Subject: subject, DNSNames: []string{"node-1"}},
+			expErrs: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				Spec: cmapi.CertificateRequestSpec{
+					Username: "system:node:node-1",
+					Groups:   []string{nodesGroup, "system:authenticated"},
+					Usages:   test.usages,
+					IsCA:     test.isCA,
+				},
+			}
+
+			var el field.ErrorList
+			if err := New(c, Options{}).evaluateNodeIdentity(context.TODO(), &el, field.NewPath("spec", "nodeIdentity"), new(cmpolicy.PolicyNodeIdentity), cr, test.csr); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
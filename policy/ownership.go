@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// Gateway API kinds are read as unstructured objects, so that the Gateway API
+// CRDs need not be installed. Each version is tried in order, so that
+// clusters which only serve an older version of the Gateway API are supported.
+var (
+	httpRouteListGK    = schema.GroupKind{Group: "gateway.networking.k8s.io", Kind: "HTTPRouteList"}
+	gatewayListGK      = schema.GroupKind{Group: "gateway.networking.k8s.io", Kind: "GatewayList"}
+	gatewayAPIVersions = []string{"v1", "v1beta1"}
+)
+
+// backedNames are the DNS names of backing objects. Wildcard names of Ingresses
+// cover a single label, whereas wildcard names of the Gateway API kinds are
+// suffix matches, covering any number of labels.
+type backedNames struct {
+	names     map[string]bool
+	wildcards map[string]bool
+	suffixes  []string
+}
+
+// add adds the DNS name of a backing object of the given kind.
+func (b *backedNames) add(kind cmpolicy.DNSNameBackingObject, name string) {
+	name = normaliseDNSName(name)
+	b.names[name] = true
+
+	if domain := strings.TrimPrefix(name, "*."); domain != name {
+		switch kind {
+		case cmpolicy.DNSNameBackingObjectHTTPRoute, cmpolicy.DNSNameBackingObjectGateway:
+			b.suffixes = append(b.suffixes, "."+domain)
+		default:
+			b.wildcards[domain] = true
+		}
+	}
+}
+
+// covers returns true if the DNS name is equal to, or covered by a wildcard
+// of, a backed name.
+func (b *backedNames) covers(dnsName string) bool {
+	dnsName = normaliseDNSName(dnsName)
+	if b.names[dnsName] {
+		return true
+	}
+
+	if i := strings.Index(dnsName, "."); i >= 0 && b.wildcards[dnsName[i+1:]] {
+		return true
+	}
+
+	for _, suffix := range b.suffixes {
+		if strings.HasSuffix(dnsName, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evaluateDNSNameOwnership will add an error for each requested DNS name that
+// is not backed by an object of one of the required kinds in the namespace of
+// the request.
+func (p *Policy) evaluateDNSNameOwnership(ctx context.Context, el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyDNSNames, dnsNames []string, namespace string) error {
+	// Allow all
+	if policy == nil || len(policy.RequireBackingObjects) == 0 {
+		return nil
+	}
+
+	backed := &backedNames{names: make(map[string]bool), wildcards: make(map[string]bool)}
+	for _, kind := range policy.RequireBackingObjects {
+		names, err := p.backedDNSNames(ctx, kind, namespace)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			backed.add(kind, name)
+		}
+	}
+
+	for _, dnsName := range dnsNames {
+		if !backed.covers(dnsName) {
+			*el = append(*el, field.Invalid(path.Child("requireBackingObjects"), dnsName,
+				fmt.Sprintf("no %v in namespace %q has this DNS name", policy.RequireBackingObjects, namespace)))
+		}
+	}
+
+	return nil
+}
+
+// backedDNSNames returns the DNS names of every object of the given kind in
+// the namespace.
+func (p *Policy) backedDNSNames(ctx context.Context, kind cmpolicy.DNSNameBackingObject, namespace string) ([]string, error) {
+	var names []string
+
+	switch kind {
+	case cmpolicy.DNSNameBackingObjectService:
+		services := new(corev1.ServiceList)
+		if err := p.List(ctx, services, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, svc := range services.Items {
+			names = append(names, serviceDNSNames(svc.Name, namespace, p.opts.ClusterDomain)...)
+		}
+
+	case cmpolicy.DNSNameBackingObjectIngress:
+		ingresses := new(networkingv1.IngressList)
+		if err := p.List(ctx, ingresses, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, ing := range ingresses.Items {
+			for _, rule := range ing.Spec.Rules {
+				names = append(names, rule.Host)
+			}
+			for _, tls := range ing.Spec.TLS {
+				names = append(names, tls.Hosts...)
+			}
+		}
+
+	case cmpolicy.DNSNameBackingObjectHTTPRoute:
+		routes, err := p.listUnstructured(ctx, httpRouteListGK, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range routes {
+			hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			names = append(names, hostnames...)
+		}
+
+	case cmpolicy.DNSNameBackingObjectGateway:
+		gateways, err := p.listUnstructured(ctx, gatewayListGK, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, gateway := range gateways {
+			listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+			for _, listener := range listeners {
+				if listener, ok := listener.(map[string]interface{}); ok {
+					if hostname, ok, _ := unstructured.NestedString(listener, "hostname"); ok {
+						names = append(names, hostname)
+					}
+				}
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// listUnstructured lists the objects of the given list kind in the namespace,
+// at the first Gateway API version served by the API server. If no version of
+// the kind is served, no objects are returned.
+func (p *Policy) listUnstructured(ctx context.Context, gk schema.GroupKind, namespace string) ([]unstructured.Unstructured, error) {
+	for _, version := range gatewayAPIVersions {
+		list := new(unstructured.UnstructuredList)
+		list.SetGroupVersionKind(gk.WithVersion(version))
+		if err := p.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	return nil, nil
+}
+
+// serviceDNSNames returns the DNS names of a Service.
+func serviceDNSNames(name, namespace, clusterDomain string) []string {
+	svc := name + "." + namespace + ".svc"
+	return []string{svc, svc + "." + clusterDomain}
+}
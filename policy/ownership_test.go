@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// noMatchClient returns a NoMatch error when listing objects of the given API
+// versions, as if they were not served by the API server.
+type noMatchClient struct {
+	client.Client
+	versions []string
+}
+
+func (c noMatchClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	gvk := list.GetObjectKind().GroupVersionKind()
+	for _, version := range c.versions {
+		if gvk.Version == version {
+			return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{version}}
+		}
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func gatewayAPIObject(version, kind string, object map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: object}
+	obj.SetAPIVersion("gateway.networking.k8s.io/" + version)
+	obj.SetKind(kind)
+	obj.SetNamespace("test")
+	obj.SetName("test")
+	return obj
+}
+
+func TestEvaluateDNSNameOwnership(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range gatewayAPIVersions {
+		for _, gk := range []schema.GroupKind{httpRouteListGK, gatewayListGK} {
+			scheme.AddKnownTypeWithName(gk.WithVersion(version), new(unstructured.UnstructuredList))
+			kind := schema.GroupVersionKind{Group: gk.Group, Version: version, Kind: strings.TrimSuffix(gk.Kind, "List")}
+			scheme.AddKnownTypeWithName(kind, new(unstructured.Unstructured))
+		}
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test"},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{Host: "*.ingress.example.com"}, {Host: "app.example.com"}},
+		},
+	}
+	httpRoute := func(version string) *unstructured.Unstructured {
+		return gatewayAPIObject(version, "HTTPRoute", map[string]interface{}{
+			"spec": map[string]interface{}{"hostnames": []interface{}{"*.route.example.com"}},
+		})
+	}
+	gateway := gatewayAPIObject("v1", "Gateway", map[string]interface{}{
+		"spec": map[string]interface{}{"listeners": []interface{}{
+			map[string]interface{}{"hostname": "*.gateway.example.com"},
+		}},
+	})
+
+	tests := map[string]struct {
+		kind     cmpolicy.DNSNameBackingObject
+		objects  []runtime.Object
+		noMatch  []string
+		dnsNames []string
+		expErrs  int
+	}{
+		"Ingress host": {
+			kind:     cmpolicy.DNSNameBackingObjectIngress,
+			objects:  []runtime.Object{ingress},
+			dnsNames: []string{"app.example.com", "APP.example.com."},
+			expErrs:  0,
+		},
+		"Ingress wildcard host covers a single label": {
+			kind:     cmpolicy.DNSNameBackingObjectIngress,
+			objects:  []runtime.Object{ingress},
+			dnsNames: []string{"foo.ingress.example.com", "*.ingress.example.com"},
+			expErrs:  0,
+		},
+		"Ingress wildcard host does not cover multiple labels or the domain": {
+			kind:     cmpolicy.DNSNameBackingObjectIngress,
+			objects:  []runtime.Object{ingress},
+			dnsNames: []string{"foo.bar.ingress.example.com", "ingress.example.com"},
+			expErrs:  2,
+		},
+		"HTTPRoute wildcard hostname covers any number of labels": {
+			kind:     cmpolicy.DNSNameBackingObjectHTTPRoute,
+			objects:  []runtime.Object{httpRoute("v1")},
+			dnsNames: []string{"foo.route.example.com", "foo.bar.route.example.com"},
+			expErrs:  0,
+		},
+		"HTTPRoute wildcard hostname does not cover the domain": {
+			kind:     cmpolicy.DNSNameBackingObjectHTTPRoute,
+			objects:  []runtime.Object{httpRoute("v1")},
+			dnsNames: []string{"route.example.com"},
+			expErrs:  1,
+		},
+		"Gateway wildcard listener hostname": {
+			kind:     cmpolicy.DNSNameBackingObjectGateway,
+			objects:  []runtime.Object{gateway},
+			dnsNames: []string{"foo.gateway.example.com", "foo.route.example.com"},
+			expErrs:  1,
+		},
+		"HTTPRoute falls back to v1beta1 if v1 is not served": {
+			kind:     cmpolicy.DNSNameBackingObjectHTTPRoute,
+			objects:  []runtime.Object{httpRoute("v1beta1")},
+			noMatch:  []string{"v1"},
+			dnsNames: []string{"foo.route.example.com"},
+			expErrs:  0,
+		},
+		"Gateway API not served": {
+			kind:     cmpolicy.DNSNameBackingObjectGateway,
+			noMatch:  gatewayAPIVersions,
+			dnsNames: []string{"foo.gateway.example.com"},
+			expErrs:  1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var c client.Client = fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(test.objects...).Build()
+			if len(test.noMatch) > 0 {
+				c = noMatchClient{Client: c, versions: test.noMatch}
+			}
+
+			var el field.ErrorList
+			policy := &cmpolicy.PolicyDNSNames{RequireBackingObjects: []cmpolicy.DNSNameBackingObject{test.kind}}
+			if err := New(c, Options{}).evaluateDNSNameOwnership(context.TODO(), &el, field.NewPath("spec"), policy, test.dnsNames, "test"); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+const (
+	// extraPodName and extraPodUID are the user info extra keys populated by
+	// the API server from the claims of a Pod bound ServiceAccount token.
+	extraPodName = "authentication.kubernetes.io/pod-name"
+	extraPodUID  = "authentication.kubernetes.io/pod-uid"
+
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+)
+
+// evaluatePodIdentity will add an error if the request was not made by a Pod,
+// or if the requested IP addresses and DNS names do not belong to that Pod.
+func (p *Policy) evaluatePodIdentity(ctx context.Context, el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyPodIdentity, cr *cmapi.CertificateRequest, ipAddresses []net.IP, dnsNames []string) error {
+	// Allow all
+	if policy == nil {
+		return nil
+	}
+
+	namespace, serviceAccount, ok := parseServiceAccountUsername(cr.Spec.Username)
+	if !ok {
+		*el = append(*el, field.Invalid(path, cr.Spec.Username, "requester is not a ServiceAccount"))
+		return nil
+	}
+
+	podName, podUID := extraValue(cr.Spec.Extra, extraPodName), extraValue(cr.Spec.Extra, extraPodUID)
+	if len(podName) == 0 || len(podUID) == 0 {
+		*el = append(*el, field.Invalid(path, cr.Spec.Username, "requester did not authenticate with a Pod bound ServiceAccount token"))
+		return nil
+	}
+
+	// The requesting Pod may be newer than the informer cache, so a missing Pod
+	// or one with a different UID is retried rather than denied.
+	pod := new(corev1.Pod)
+	if err := p.Get(ctx, client.ObjectKey{Namespace: namespace, Name: podName}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("requesting Pod %s/%s not found: %w", namespace, podName, err)
+		}
+		return err
+	}
+
+	if string(pod.UID) != podUID {
+		return fmt.Errorf("requesting Pod %s/%s has UID %q, expected %q", namespace, podName, pod.UID, podUID)
+	}
+	if pod.Spec.ServiceAccountName != serviceAccount {
+		*el = append(*el, field.Invalid(path, cr.Spec.Username,
+			fmt.Sprintf("requesting Pod runs as ServiceAccount %q", pod.Spec.ServiceAccountName)))
+		return nil
+	}
+
+	podIPs := make(map[string]bool)
+	for _, podIP := range pod.Status.PodIPs {
+		if ip := net.ParseIP(podIP.IP); ip != nil {
+			podIPs[ip.String()] = true
+		}
+	}
+	if ip := net.ParseIP(pod.Status.PodIP); ip != nil {
+		podIPs[ip.String()] = true
+	}
+	for _, ip := range ipAddresses {
+		if !podIPs[ip.String()] {
+			*el = append(*el, field.Invalid(path.Child("ipAddresses"), ip.String(), "not an IP address of the requesting Pod"))
+		}
+	}
+
+	if len(dnsNames) == 0 {
+		return nil
+	}
+
+	podDNSNames := make(map[string]bool)
+	for _, name := range p.podDNSNames(pod) {
+		podDNSNames[normaliseDNSName(name)] = true
+	}
+	if policy.AllowServiceDNSNames == nil || *policy.AllowServiceDNSNames {
+		names, err := p.selectingServiceDNSNames(ctx, pod)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			podDNSNames[normaliseDNSName(name)] = true
+		}
+	}
+	for _, dnsName := range dnsNames {
+		if !podDNSNames[normaliseDNSName(dnsName)] {
+			*el = append(*el, field.Invalid(path.Child("dnsNames"), dnsName, "not a DNS name of the requesting Pod"))
+		}
+	}
+
+	return nil
+}
+
+// podDNSNames returns the DNS names of a Pod derived from its hostname and
+// subdomain.
+func (p *Policy) podDNSNames(pod *corev1.Pod) []string {
+	hostname := pod.Spec.Hostname
+	if len(hostname) == 0 {
+		hostname = pod.Name
+	}
+
+	names := []string{hostname}
+	if len(pod.Spec.Subdomain) > 0 {
+		for _, svc := range serviceDNSNames(pod.Spec.Subdomain, pod.Namespace, p.opts.ClusterDomain) {
+			names = append(names, hostname+"."+svc)
+		}
+	}
+
+	return names
+}
+
+// selectingServiceDNSNames returns the DNS names of every Service whose
+// selector matches the Pod.
+func (p *Policy) selectingServiceDNSNames(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	services := new(corev1.ServiceList)
+	if err := p.List(ctx, services, client.InNamespace(pod.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, svc := range services.Items {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			names = append(names, serviceDNSNames(svc.Name, svc.Namespace, p.opts.ClusterDomain)...)
+		}
+	}
+
+	return names, nil
+}
+
+// parseServiceAccountUsername returns the namespace and name of the
+// ServiceAccount of the given username. Returns false if the username is not
+// that of a ServiceAccount.
+func parseServiceAccountUsername(username string) (string, string, bool) {
+	if !strings.HasPrefix(username, serviceAccountUsernamePrefix) {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(username, serviceAccountUsernamePrefix), ":")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// extraValue returns the single value of the given user info extra key, or
+// the empty string if the key does not have exactly one value.
+func extraValue(extra map[string][]string, key string) string {
+	if values := extra[key]; len(values) == 1 {
+		return values[0]
+	}
+	return ""
+}
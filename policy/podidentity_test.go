@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestEvaluatePodIdentity(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "web-0", UID: "uid-1", Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{ServiceAccountName: "web", Hostname: "web-0", Subdomain: "web-headless"},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.1", PodIPs: []corev1.PodIP{{IP: "10.0.0.1"}, {IP: "fd00::1"}}},
+	}
+	selecting := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "web"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+	}
+	notSelecting := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "db"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "db"}},
+	}
+	noSelector := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "external"},
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod, selecting, notSelecting, noSelector).Build()
+
+	podExtra := map[string][]string{extraPodName: {"web-0"}, extraPodUID: {"uid-1"}}
+	no := false
+
+	tests := map[string]struct {
+		policy      *cmpolicy.PolicyPodIdentity
+		username    string
+		extra       map[string][]string
+		ipAddresses []net.IP
+		dnsNames    []string
+		expErrs     int
+		expErr      bool
+	}{
+		"no policy should allow": {
+			policy:   nil,
+			username: "alice",
+			expErrs:  0,
+		},
+		"requester which is not a ServiceAccount should deny": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "alice",
+			extra:    podExtra,
+			expErrs:  1,
+		},
+		"token which is not Pod bound should deny": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "system:serviceaccount:test:web",
+			expErrs:  1,
+		},
+		"Pod running as a different ServiceAccount should deny": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "system:serviceaccount:test:other",
+			extra:    podExtra,
+			expErrs:  1,
+		},
+		"Pod missing from the cache should error": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "system:serviceaccount:test:web",
+			extra:    map[string][]string{extraPodName: {"web-1"}, extraPodUID: {"uid-2"}},
+			expErr:   true,
+		},
+		"Pod with a different UID should error": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "system:serviceaccount:test:web",
+			extra:    map[string][]string{extraPodName: {"web-0"}, extraPodUID: {"uid-2"}},
+			expErr:   true,
+		},
+		"Pod IP addresses should allow": {
+			policy:      new(cmpolicy.PolicyPodIdentity),
+			username:    "system:serviceaccount:test:web",
+			extra:       podExtra,
+			ipAddresses: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("fd00:0::1")},
+			expErrs:     0,
+		},
+		"IP address which is not the Pod's should deny": {
+			policy:      new(cmpolicy.PolicyPodIdentity),
+			username:    "system:serviceaccount:test:web",
+			extra:       podExtra,
+			ipAddresses: []net.IP{net.ParseIP("10.0.0.2")},
+			expErrs:     1,
+		},
+		"Pod hostname and subdomain DNS names should allow": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "system:serviceaccount:test:web",
+			extra:    podExtra,
+			dnsNames: []string{"web-0", "web-0.web-headless.test.svc", "Web-0.web-headless.test.svc.cluster.local."},
+			expErrs:  0,
+		},
+		"selecting Service DNS names should allow": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "system:serviceaccount:test:web",
+			extra:    podExtra,
+			dnsNames: []string{"web.test.svc", "web.test.svc.cluster.local"},
+			expErrs:  0,
+		},
+		"selecting Service DNS names when Service DNS names are not allowed should deny": {
+			policy:   &cmpolicy.PolicyPodIdentity{AllowServiceDNSNames: &no},
+			username: "system:serviceaccount:test:web",
+			extra:    podExtra,
+			dnsNames: []string{"web.test.svc", "web-0"},
+			expErrs:  1,
+		},
+		"non-selecting Service DNS names should deny": {
+			policy:   new(cmpolicy.PolicyPodIdentity),
+			username: "system:serviceaccount:test:web",
+			extra:    podExtra,
+			dnsNames: []string{"db.test.svc", "external.test.svc"},
+			expErrs:  2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test"},
+				Spec:       cmapi.CertificateRequestSpec{Username: test.username, Extra: test.extra},
+			}
+
+			var el field.ErrorList
+			err := New(c, Options{ClusterDomain: "cluster.local"}).evaluatePodIdentity(context.TODO(), &el, field.NewPath("spec", "podIdentity"), test.policy, cr, test.ipAddresses, test.dnsNames)
+			if (err != nil) != test.expErr {
+				t.Fatalf("unexpected error: exp=%t got=%v", test.expErr, err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestParseServiceAccountUsername(t *testing.T) {
+	tests := map[string]struct {
+		username     string
+		expNamespace string
+		expName      string
+		expOK        bool
+	}{
+		"ServiceAccount": {
+			username:     "system:serviceaccount:test:web",
+			expNamespace: "test",
+			expName:      "web",
+			expOK:        true,
+		},
+		"user": {
+			username: "alice",
+			expOK:    false,
+		},
+		"missing name": {
+			username: "system:serviceaccount:test",
+			expOK:    false,
+		},
+		"empty namespace": {
+			username: "system:serviceaccount::web",
+			expOK:    false,
+		},
+		"empty name": {
+			username: "system:serviceaccount:test:",
+			expOK:    false,
+		},
+		"too many segments": {
+			username: "system:serviceaccount:test:web:extra",
+			expOK:    false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			namespace, name, ok := parseServiceAccountUsername(test.username)
+			if namespace != test.expNamespace || name != test.expName || ok != test.expOK {
+				t.Errorf("unexpected result: exp=(%q, %q, %t) got=(%q, %q, %t)",
+					test.expNamespace, test.expName, test.expOK, namespace, name, ok)
+			}
+		})
+	}
+}
+
+func TestExtraValue(t *testing.T) {
+	tests := map[string]struct {
+		extra map[string][]string
+		exp   string
+	}{
+		"single value": {
+			extra: map[string][]string{extraPodName: {"web-0"}},
+			exp:   "web-0",
+		},
+		"missing key": {
+			extra: map[string][]string{extraPodUID: {"uid-1"}},
+			exp:   "",
+		},
+		"no values": {
+			extra: map[string][]string{extraPodName: {}},
+			exp:   "",
+		},
+		"multiple values": {
+			extra: map[string][]string{extraPodName: {"web-0", "web-1"}},
+			exp:   "",
+		},
+		"nil extra": {
+			extra: nil,
+			exp:   "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := extraValue(test.extra, extraPodName); got != test.exp {
+				t.Errorf("unexpected value: exp=%q got=%q", test.exp, got)
+			}
+		})
+	}
+}
@@ -18,14 +18,13 @@ package policy
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-	authzv1 "k8s.io/api/authorization/v1"
-	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+	"github.com/cert-manager/policy-approver/policy/requester"
 )
 
 var (
@@ -34,20 +33,59 @@ var (
 	MissingBindingMessage = "No CertificateRequestPolicies bound"
 )
 
+// Options configure how CertificateRequests are evaluated.
+type Options struct {
+	// ClusterDomain is the DNS domain of the cluster, used to derive the DNS
+	// names of Services and Pods.
+	ClusterDomain string
+
+	// DefaultDuration is the duration requests without a duration are
+	// evaluated against, unless their issuer sets its own default.
+	DefaultDuration time.Duration
+
+	// ClusterResourceNamespace is the namespace of the Secrets referenced by
+	// ClusterIssuers.
+	ClusterResourceNamespace string
+
+	// SecretReader, if set, reads issuer Secrets, so that Secrets need not be
+	// cached. Defaults to the Policy client.
+	SecretReader client.Reader
+
+	// RequesterSigner, if set, verifies the requester annotation of the
+	// Certificate owning a CertificateRequest. Bindings and requester
+	// constraints are then evaluated against that requester, rather than the
+	// requester of the CertificateRequest.
+	RequesterSigner *requester.Signer
+
+	// CertManagerUsername is the username of the cert-manager controller. The
+	// requester of a CertificateRequest is only replaced by the requester of
+	// its owning Certificate if the CertificateRequest was created by this
+	// user, so that other requesters cannot claim a Certificate's requester
+	// by setting an owner reference.
+	CertManagerUsername string
+
+	// Combination is how the policies bound to a request are combined, unless
+	// overridden by the request's Namespace. Defaults to AnyOf.
+	Combination Combination
+}
+
 // Policy is responsible for evaluating whether incoming CertificateRequests
 // should be approved, checking CertificateRequestPolicys.
 type Policy struct {
 	client.Client
+	opts Options
 }
 
-func New(client client.Client) *Policy {
+func New(client client.Client, opts Options) *Policy {
 	return &Policy{
 		Client: client,
+		opts:   opts,
 	}
 }
 
 // Evaluate will evaluate whether the incoming CertificateRequest should be
-// approved.
+// approved, combining the bound CertificateRequestPolicies as configured for
+// the request's namespace.
 // - Consumers should consider a true response meaning the CertificateRequest
 //   is **approved**.
 // - Consumers should consider a false response and no error to mean the
@@ -66,70 +104,63 @@ func (p *Policy) Evaluate(ctx context.Context, cr *cmapi.CertificateRequest) (bo
 		return true, NoCRPExistMessage, nil
 	}
 
-	policyErrors := make(map[string]string)
-	extra := make(map[string]authzv1.ExtraValue)
-	for k, v := range cr.Spec.Extra {
-		extra[k] = v
+	// Evaluate against the requester of the owning Certificate, if configured
+	cr, reason, err := p.resolveRequester(ctx, cr)
+	if err != nil {
+		return false, ErrorMessage, err
+	}
+	if cr == nil {
+		return false, reason, nil
 	}
 
+	combination, reason, err := p.combination(ctx, cr.Namespace)
+	if err != nil {
+		return false, ErrorMessage, err
+	}
+	if len(reason) > 0 {
+		return false, reason, nil
+	}
+
+	var (
+		bound []*cmpolicy.CertificateRequestPolicy
+		seen  = make(map[string]bool)
+	)
+
 	// Check namespaced scope, then cluster scope
 	for _, ns := range []string{cr.Namespace, ""} {
-		for _, crp := range crps.Items {
+		for i := range crps.Items {
+			crp := &crps.Items[i]
 
 			// Don't check the same CertificateRequestPolicy more than once
-			if _, ok := policyErrors[crp.Name]; ok {
+			if seen[crp.Name] {
 				continue
 			}
 
-			// Perform subject access review for this CertificateRequestPolicy
-			rev := &authzv1.SubjectAccessReview{
-				Spec: authzv1.SubjectAccessReviewSpec{
-					User:   cr.Spec.Username,
-					Groups: cr.Spec.Groups,
-					Extra:  extra,
-					UID:    cr.Spec.UID,
-
-					ResourceAttributes: &authzv1.ResourceAttributes{
-						Group:     "policy.cert-manager.io",
-						Resource:  "certificaterequestpolicies",
-						Name:      crp.Name,
-						Namespace: ns,
-						Verb:      "use",
-					},
-				},
-			}
-			if err := p.Create(ctx, rev); err != nil {
-				return false, ErrorMessage, err
-			}
-
 			// Don't perform evaluation if this CertificateRequestPolicy is not bound
-			if !rev.Status.Allowed {
-				continue
-			}
-
-			var el field.ErrorList
-			if err := EvaluateCertificateRequest(&el, &crp, cr); err != nil {
+			ok, err := p.isBound(ctx, crp, cr, ns)
+			if err != nil {
 				return false, ErrorMessage, err
 			}
-
-			// If no evaluation errors resulting from this policy, return approved
-			// with the name of the CertificateRequestPolicy.
-			if len(el) == 0 {
-				return true, fmt.Sprintf("Approved by CertificateRequestPolicy %q", crp.Name), nil
+			if !ok {
+				continue
 			}
 
-			// Collect policy errors by the CertificateRequestPolicy name, so errors
-			// can be bubbled to the CertificateRequest condition
-			policyErrors[crp.Name] = el.ToAggregate().Error()
+			seen[crp.Name] = true
+			bound = append(bound, crp)
 		}
 	}
 
 	// If policies exist, but none are bound
-	if len(policyErrors) == 0 {
+	if len(bound) == 0 {
 		return false, MissingBindingMessage, nil
 	}
 
-	// Return with all policies that we consulted, and their errors to why the
-	// request was denied.
-	return false, fmt.Sprintf("No policy approved this request: %v", policyErrors), nil
+	switch combination {
+	case CombinationAllOf:
+		return p.evaluateAllOf(ctx, bound, cr)
+	case CombinationLayered:
+		return p.evaluateLayered(ctx, bound, cr)
+	default:
+		return p.evaluateAnyOf(ctx, bound, cr)
+	}
 }
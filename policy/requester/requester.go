@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requester signs and verifies the identity of the requester which
+// last modified the spec of a Certificate. The identity is stamped onto the
+// Certificate as an annotation by an admission webhook, so that the
+// CertificateRequests cert-manager creates for it can be evaluated against the
+// requester of the Certificate rather than cert-manager itself.
+package requester
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AnnotationKey is the annotation on Certificates holding the signed identity
+// of the requester.
+const AnnotationKey = "policy.cert-manager.io/requester"
+
+// minKeySize is the minimum size of a signing key in bytes.
+const minKeySize = 32
+
+// Identity is the user info of a requester.
+type Identity struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// payload binds an Identity to a Certificate, so that a signed annotation
+// cannot be copied onto another Certificate.
+type payload struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Identity  Identity `json:"identity"`
+}
+
+// Signer signs and verifies Identities with an HMAC-SHA256 key.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner returns a Signer using the given key. Returns an error if the key
+// is shorter than 32 bytes.
+func NewSigner(key []byte) (*Signer, error) {
+	if len(key) < minKeySize {
+		return nil, fmt.Errorf("signing key must be at least %d bytes, got %d", minKeySize, len(key))
+	}
+	return &Signer{key: key}, nil
+}
+
+// Sign returns the annotation value of the Identity for the Certificate with
+// the given namespace and name.
+func (s *Signer) Sign(namespace, name string, identity Identity) (string, error) {
+	data, err := json.Marshal(payload{Namespace: namespace, Name: name, Identity: identity})
+	if err != nil {
+		return "", err
+	}
+
+	return encode(data) + "." + encode(s.mac(data)), nil
+}
+
+// Verify returns the Identity of the annotation value, if it was signed by
+// this Signer for the Certificate with the given namespace and name.
+func (s *Signer) Verify(namespace, name, value string) (*Identity, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return nil, errors.New("malformed requester annotation")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed requester annotation: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed requester annotation: %w", err)
+	}
+
+	if !hmac.Equal(sig, s.mac(data)) {
+		return nil, errors.New("requester annotation has an invalid signature")
+	}
+
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("malformed requester annotation: %w", err)
+	}
+	if p.Namespace != namespace || p.Name != name {
+		return nil, fmt.Errorf("requester annotation was signed for Certificate %s/%s", p.Namespace, p.Name)
+	}
+
+	return &p.Identity, nil
+}
+
+func (s *Signer) mac(data []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requester
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	signer, err := NewSigner([]byte(strings.Repeat("a", minKeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSigner, err := NewSigner([]byte(strings.Repeat("b", minKeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity := Identity{
+		Username: "alice",
+		Groups:   []string{"pki-admins", "system:authenticated"},
+		Extra:    map[string][]string{"department": {"security"}},
+	}
+	value, err := signer.Sign("team-a", "my-cert", identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		signer    *Signer
+		namespace string
+		name      string
+		value     string
+		expErr    bool
+	}{
+		"signed for the Certificate": {
+			signer:    signer,
+			namespace: "team-a",
+			name:      "my-cert",
+			value:     value,
+			expErr:    false,
+		},
+		"signed for a Certificate with a different name": {
+			signer:    signer,
+			namespace: "team-a",
+			name:      "other-cert",
+			value:     value,
+			expErr:    true,
+		},
+		"signed for a Certificate in a different namespace": {
+			signer:    signer,
+			namespace: "team-b",
+			name:      "my-cert",
+			value:     value,
+			expErr:    true,
+		},
+		"signed with a different key": {
+			signer:    otherSigner,
+			namespace: "team-a",
+			name:      "my-cert",
+			value:     value,
+			expErr:    true,
+		},
+		"tampered payload": {
+			signer:    signer,
+			namespace: "team-a",
+			name:      "my-cert",
+			value:     "e30" + value[strings.Index(value, "."):],
+			expErr:    true,
+		},
+		"malformed value": {
+			signer:    signer,
+			namespace: "team-a",
+			name:      "my-cert",
+			value:     "alice",
+			expErr:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := test.signer.Verify(test.namespace, test.name, test.value)
+			if (err != nil) != test.expErr {
+				t.Fatalf("unexpected error: exp=%t got=%v", test.expErr, err)
+			}
+			if err == nil && !reflect.DeepEqual(*got, identity) {
+				t.Errorf("unexpected identity: exp=%+v got=%+v", identity, *got)
+			}
+		})
+	}
+}
+
+func TestNewSignerShortKey(t *testing.T) {
+	if _, err := NewSigner([]byte("short")); err == nil {
+		t.Error("expected error for short key")
+	}
+}
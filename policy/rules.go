@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// evaluateRules will add an error if the request does not satisfy at least one
+// of the rules which match it. If no rules are defined, all requests are
+// allowed.
+func (p *Policy) evaluateRules(ctx context.Context, el *field.ErrorList, path *field.Path, rules []cmpolicy.PolicyRule, cr *cmapi.CertificateRequest, csr *x509.CertificateRequest, duration *metav1.Duration) error {
+	// Allow all
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var (
+		matched bool
+		ruleEl  field.ErrorList
+	)
+	for i, rule := range rules {
+		rulePath := path.Index(i)
+		if len(rule.Name) > 0 {
+			rulePath = path.Key(rule.Name)
+		}
+
+		ok, err := p.matchesRule(ctx, el, rulePath.Child("match"), &rule.Match, cr, csr)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		matched = true
+
+		var constraintsEl field.ErrorList
+		if err := p.evaluateConstraints(ctx, &constraintsEl, rulePath, &rule.PolicyConstraints, cr, csr, duration); err != nil {
+			return err
+		}
+		if len(constraintsEl) == 0 {
+			return nil
+		}
+		ruleEl = append(ruleEl, constraintsEl...)
+	}
+
+	if !matched {
+		*el = append(*el, field.Invalid(path, len(rules), "no rule matches the request"))
+		return nil
+	}
+
+	*el = append(*el, ruleEl...)
+	return nil
+}
+
+// matchesRule returns true if the request matches all conditions of the rule
+// match. An empty match matches all requests. Invalid selectors match no
+// request, and add an error.
+func (p *Policy) matchesRule(ctx context.Context, el *field.ErrorList, path *field.Path, match *cmpolicy.PolicyRuleMatch, cr *cmapi.CertificateRequest, csr *x509.CertificateRequest) (bool, error) {
+	if match.KeyAlgorithm != nil {
+		alg, _, err := parsePublicKey(csr.PublicKey)
+		if err != nil || alg != *match.KeyAlgorithm {
+			return false, nil
+		}
+	}
+
+	if match.IsCA != nil && cr.Spec.IsCA != *match.IsCA {
+		return false, nil
+	}
+
+	if match.Issuer != nil {
+		ok, err := p.issuerMatches(ctx, el, path.Child("issuer"), *match.Issuer, cr)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if match.Usages != nil {
+		usages := cr.Spec.Usages
+		if len(usages) == 0 {
+			usages = cmapi.DefaultKeyUsages()
+		}
+		for _, usage := range *match.Usages {
+			if !containsUsage(usages, usage) {
+				return false, nil
+			}
+		}
+	}
+
+	if match.Wildcard != nil && hasWildcardDNSName(csr.DNSNames) != *match.Wildcard {
+		return false, nil
+	}
+
+	if match.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(match.NamespaceSelector)
+		if err != nil {
+			*el = append(*el, field.Invalid(path.Child("namespaceSelector"), match.NamespaceSelector, fmt.Sprintf("invalid selector: %s", err)))
+			return false, nil
+		}
+
+		ns := new(corev1.Namespace)
+		if err := p.Get(ctx, client.ObjectKey{Name: cr.Namespace}, ns); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// hasWildcardDNSName returns true if any of the DNS names is a wildcard.
+func hasWildcardDNSName(dnsNames []string) bool {
+	for _, dnsName := range dnsNames {
+		if strings.Contains(dnsName, "*") {
+			return true
+		}
+	}
+	return false
+}
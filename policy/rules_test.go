@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestEvaluateRules(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmapi.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"env": "prod"}}},
+		&cmapi.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "ca", Labels: map[string]string{"tier": "internal"}}},
+	).Build()
+
+	isCA, notCA := true, false
+	prodSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	invalidSelector := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: "Bogus"}}}
+	exampleDNSNames := cmpolicy.PolicyConstraints{AllowedDNSNames: &[]string{"*.example.com"}}
+
+	tests := map[string]struct {
+		rules   []cmpolicy.PolicyRule
+		dnsName string
+		expErrs int
+	}{
+		"no rules: allow all": {
+			rules:   nil,
+			dnsName: "foo.acme.com",
+			expErrs: 0,
+		},
+		"matching rule satisfied": {
+			rules:   []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{IsCA: &notCA}, PolicyConstraints: exampleDNSNames}},
+			dnsName: "foo.example.com",
+			expErrs: 0,
+		},
+		"matching rule not satisfied": {
+			rules:   []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{IsCA: &notCA}, PolicyConstraints: exampleDNSNames}},
+			dnsName: "foo.acme.com",
+			expErrs: 1,
+		},
+		"no rule matches": {
+			rules:   []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{IsCA: &isCA}}},
+			dnsName: "foo.example.com",
+			expErrs: 1,
+		},
+		"second rule satisfied": {
+			rules: []cmpolicy.PolicyRule{
+				{Name: "example", PolicyConstraints: exampleDNSNames},
+				{Name: "acme", PolicyConstraints: cmpolicy.PolicyConstraints{AllowedDNSNames: &[]string{"*.acme.com"}}},
+			},
+			dnsName: "foo.acme.com",
+			expErrs: 0,
+		},
+		"namespace selector matches": {
+			rules:   []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{NamespaceSelector: prodSelector}, PolicyConstraints: exampleDNSNames}},
+			dnsName: "foo.example.com",
+			expErrs: 0,
+		},
+		"namespace selector does not match": {
+			rules: []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			}}},
+			dnsName: "foo.example.com",
+			expErrs: 1,
+		},
+		"issuer selector matches": {
+			rules: []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{Issuer: &cmpolicy.PolicyIssuer{
+				Kind:     cmapi.ClusterIssuerKind,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "internal"}},
+			}}, PolicyConstraints: exampleDNSNames}},
+			dnsName: "foo.example.com",
+			expErrs: 0,
+		},
+		"invalid namespace selector is reported": {
+			rules:   []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{NamespaceSelector: invalidSelector}}},
+			dnsName: "foo.example.com",
+			expErrs: 2,
+		},
+		"invalid issuer selector is reported": {
+			rules: []cmpolicy.PolicyRule{{Match: cmpolicy.PolicyRuleMatch{Issuer: &cmpolicy.PolicyIssuer{
+				Kind:     cmapi.ClusterIssuerKind,
+				Selector: invalidSelector,
+			}}}},
+			dnsName: "foo.example.com",
+			expErrs: 2,
+		},
+		"invalid selector is reported even if a later rule is satisfied": {
+			rules: []cmpolicy.PolicyRule{
+				{Match: cmpolicy.PolicyRuleMatch{NamespaceSelector: invalidSelector}},
+				{PolicyConstraints: exampleDNSNames},
+			},
+			dnsName: "foo.example.com",
+			expErrs: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test"},
+				Spec: cmapi.CertificateRequestSpec{
+					IssuerRef: cmmeta.ObjectReference{Name: "ca", Kind: cmapi.ClusterIssuerKind},
+				},
+			}
+			csr := mustParseCSR(t, &x509.CertificateRequest{DNSNames: []string{test.dnsName}})
+
+			var el field.ErrorList
+			if err := New(c, Options{}).evaluateRules(context.TODO(), &el, field.NewPath("spec", "rules"), test.rules, cr, csr, nil); err != nil {
+				t.Fatal(err)
+			}
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
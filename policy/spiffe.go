@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// spiffeUsages are the usages a SPIFFE X.509-SVID may be requested with.
+var spiffeUsages = []cmapi.KeyUsage{
+	cmapi.UsageClientAuth,
+	cmapi.UsageServerAuth,
+	cmapi.UsageDigitalSignature,
+	cmapi.UsageKeyEncipherment,
+	cmapi.UsageKeyAgreement,
+}
+
+// evaluateSPIFFE will add an error if the request is not for the SPIFFE ID of
+// the requesting ServiceAccount.
+func evaluateSPIFFE(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicySPIFFE, cr *cmapi.CertificateRequest, csr *x509.CertificateRequest) {
+	// Allow all
+	if policy == nil {
+		return
+	}
+
+	namespace, serviceAccount, ok := parseServiceAccountUsername(cr.Spec.Username)
+	if !ok {
+		*el = append(*el, field.Invalid(path, cr.Spec.Username, "requester is not a ServiceAccount"))
+		return
+	}
+	if namespace != cr.Namespace {
+		*el = append(*el, field.Invalid(path, cr.Spec.Username,
+			fmt.Sprintf("requesting ServiceAccount is not in the namespace of the request %q", cr.Namespace)))
+		return
+	}
+
+	spiffeID := fmt.Sprintf("spiffe://%s/ns/%s/sa/%s", policy.TrustDomain, namespace, serviceAccount)
+	if len(csr.URIs) != 1 || csr.URIs[0].String() != spiffeID {
+		var uris []string
+		for _, uri := range csr.URIs {
+			uris = append(uris, uri.String())
+		}
+		*el = append(*el, field.Invalid(path.Child("uris"), uris, fmt.Sprintf("must be exactly [%q]", spiffeID)))
+	}
+
+	if policy.AllowAdditionalSANs == nil || !*policy.AllowAdditionalSANs {
+		if len(csr.DNSNames) > 0 {
+			*el = append(*el, field.Invalid(path.Child("allowAdditionalSANs"), csr.DNSNames, "DNS names are not allowed alongside the SPIFFE ID"))
+		}
+		if len(csr.IPAddresses) > 0 {
+			var ips []string
+			for _, ip := range csr.IPAddresses {
+				ips = append(ips, ip.String())
+			}
+			*el = append(*el, field.Invalid(path.Child("allowAdditionalSANs"), ips, "IP addresses are not allowed alongside the SPIFFE ID"))
+		}
+		if len(csr.EmailAddresses) > 0 {
+			*el = append(*el, field.Invalid(path.Child("allowAdditionalSANs"), csr.EmailAddresses, "email addresses are not allowed alongside the SPIFFE ID"))
+		}
+	}
+
+	for _, usage := range cr.Spec.Usages {
+		if !containsUsage(spiffeUsages, usage) {
+			*el = append(*el, field.Invalid(path.Child("usages"), usage, fmt.Sprintf("SPIFFE IDs may only be requested with %v", spiffeUsages)))
+		}
+	}
+
+	if cr.Spec.IsCA {
+		*el = append(*el, field.Invalid(path.Child("isCA"), cr.Spec.IsCA, "SPIFFE IDs may not be requested for a CA"))
+	}
+}
+
+// containsUsage returns true if the usage is in the list.
+func containsUsage(usages []cmapi.KeyUsage, usage cmapi.KeyUsage) bool {
+	for _, u := range usages {
+		if u == usage {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"crypto/x509"
+	"net"
+	"net/url"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+func TestEvaluateSPIFFE(t *testing.T) {
+	mustParseURL := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	spiffeID := mustParseURL("spiffe://example.org/ns/test/sa/web")
+	yes := true
+
+	tests := map[string]struct {
+		policy   *cmpolicy.PolicySPIFFE
+		username string
+		usages   []cmapi.KeyUsage
+		isCA     bool
+		csr      *x509.CertificateRequest
+		expErrs  int
+	}{
+		"no policy should allow": {
+			policy:   nil,
+			username: "alice",
+			csr:      &x509.CertificateRequest{},
+			expErrs:  0,
+		},
+		"SPIFFE ID of the requesting ServiceAccount should allow": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:test:web",
+			usages:   []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageClientAuth, cmapi.UsageServerAuth},
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{spiffeID}},
+			expErrs:  0,
+		},
+		"requester which is not a ServiceAccount should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "alice",
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{spiffeID}},
+			expErrs:  1,
+		},
+		"ServiceAccount in another namespace should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:other:web",
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{mustParseURL("spiffe://example.org/ns/other/sa/web")}},
+			expErrs:  1,
+		},
+		"SPIFFE ID of another ServiceAccount should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:test:db",
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{spiffeID}},
+			expErrs:  1,
+		},
+		"SPIFFE ID of another trust domain should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.com"},
+			username: "system:serviceaccount:test:web",
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{spiffeID}},
+			expErrs:  1,
+		},
+		"no URIs should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:test:web",
+			csr:      &x509.CertificateRequest{},
+			expErrs:  1,
+		},
+		"additional URI should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:test:web",
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{spiffeID, mustParseURL("https://example.org")}},
+			expErrs:  1,
+		},
+		"additional SANs should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:test:web",
+			csr: &x509.CertificateRequest{
+				URIs:           []*url.URL{spiffeID},
+				DNSNames:       []string{"web.test.svc"},
+				IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+				EmailAddresses: []string{"web@example.org"},
+			},
+			expErrs: 3,
+		},
+		"additional SANs when allowed should allow": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org", AllowAdditionalSANs: &yes},
+			username: "system:serviceaccount:test:web",
+			csr: &x509.CertificateRequest{
+				URIs:        []*url.URL{spiffeID},
+				DNSNames:    []string{"web.test.svc"},
+				IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+			},
+			expErrs: 0,
+		},
+		"usage which is not allowed for SPIFFE IDs should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:test:web",
+			usages:   []cmapi.KeyUsage{cmapi.UsageClientAuth, cmapi.UsageCertSign, cmapi.UsageCodeSigning},
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{spiffeID}},
+			expErrs:  2,
+		},
+		"CA should deny": {
+			policy:   &cmpolicy.PolicySPIFFE{TrustDomain: "example.org"},
+			username: "system:serviceaccount:test:web",
+			isCA:     true,
+			csr:      &x509.CertificateRequest{URIs: []*url.URL{spiffeID}},
+			expErrs:  1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test"},
+				Spec: cmapi.CertificateRequestSpec{
+					Username: test.username,
+					Usages:   test.usages,
+					IsCA:     test.isCA,
+				},
+			}
+
+			var el field.ErrorList
+			evaluateSPIFFE(&el, field.NewPath("spec", "spiffe"), test.policy, cr, test.csr)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+	"github.com/cert-manager/policy-approver/policy/checks"
+)
+
+// Object identifiers of the subject attribute types that the policy has
+// dedicated fields for.
+var (
+	oidCommonName         = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidSerialNumber       = asn1.ObjectIdentifier{2, 5, 4, 5}
+	oidCountry            = asn1.ObjectIdentifier{2, 5, 4, 6}
+	oidLocality           = asn1.ObjectIdentifier{2, 5, 4, 7}
+	oidProvince           = asn1.ObjectIdentifier{2, 5, 4, 8}
+	oidStreetAddress      = asn1.ObjectIdentifier{2, 5, 4, 9}
+	oidOrganization       = asn1.ObjectIdentifier{2, 5, 4, 10}
+	oidOrganizationalUnit = asn1.ObjectIdentifier{2, 5, 4, 11}
+	oidTitle              = asn1.ObjectIdentifier{2, 5, 4, 12}
+	oidPostalCode         = asn1.ObjectIdentifier{2, 5, 4, 17}
+	oidUID                = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}
+	oidDomainComponent    = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+	oidEmailAddress       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+)
+
+// singleValuedAttributes are the attribute types which the policy evaluates as
+// a single value, and so may only occur once in a subject.
+var singleValuedAttributes = []struct {
+	oid  asn1.ObjectIdentifier
+	name string
+}{
+	{oidCommonName, "common name"},
+	{oidSerialNumber, "serial number"},
+}
+
+// attributeTypeNames are the short names of attribute types used when
+// serialising a distinguished name. These are the names defined by RFC 4514,
+// plus those used by crypto/x509/pkix.
+var attributeTypeNames = map[string]string{
+	oidCommonName.String():         "CN",
+	oidSerialNumber.String():       "SERIALNUMBER",
+	oidCountry.String():            "C",
+	oidLocality.String():           "L",
+	oidProvince.String():           "ST",
+	oidStreetAddress.String():      "STREET",
+	oidOrganization.String():       "O",
+	oidOrganizationalUnit.String(): "OU",
+	oidPostalCode.String():         "POSTALCODE",
+	oidUID.String():                "UID",
+	oidDomainComponent.String():    "DC",
+}
+
+// decodeSubject decodes the DER encoded subject of a request, preserving the
+// order of relative distinguished names and multi-valued RDNs.
+func decodeSubject(raw []byte) (pkix.RDNSequence, error) {
+	var rdns pkix.RDNSequence
+	rest, err := asn1.Unmarshal(raw, &rdns)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after X.509 subject")
+	}
+	return rdns, nil
+}
+
+// subjectValues returns the values of every attribute in the subject, keyed by
+// the dot separated OID of the attribute type.
+func subjectValues(rdns pkix.RDNSequence) map[string][]string {
+	values := make(map[string][]string)
+	for _, rdn := range rdns {
+		for _, atv := range rdn {
+			oid := atv.Type.String()
+			values[oid] = append(values[oid], attributeValueString(atv.Value))
+		}
+	}
+	return values
+}
+
+// attributeValueString returns the string form of a decoded attribute value.
+func attributeValueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// distinguishedName returns the RFC 4514 string of the subject. As defined by
+// RFC 4514, RDNs are written starting from the last element of the sequence,
+// and attributes of a multi-valued RDN are joined with '+'. Attribute types
+// without a short name are written as their dotted OID, followed by the string
+// value of the attribute.
+func distinguishedName(rdns pkix.RDNSequence) string {
+	var sb strings.Builder
+	for i := len(rdns) - 1; i >= 0; i-- {
+		if i < len(rdns)-1 {
+			sb.WriteByte(',')
+		}
+		for j, atv := range rdns[i] {
+			if j > 0 {
+				sb.WriteByte('+')
+			}
+			oid := atv.Type.String()
+			if name, ok := attributeTypeNames[oid]; ok {
+				oid = name
+			}
+			sb.WriteString(oid)
+			sb.WriteByte('=')
+			sb.WriteString(escapeAttributeValue(attributeValueString(atv.Value)))
+		}
+	}
+	return sb.String()
+}
+
+// escapeAttributeValue escapes an attribute value as defined by RFC 4514
+// section 2.4.
+func escapeAttributeValue(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == ',' || r == '+' || r == '"' || r == '\\' || r == '<' || r == '>' || r == ';',
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(s)-1 && r == ' ':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == 0:
+			sb.WriteString("\\00")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// evaluateSubjectAttributes will add errors for subject attributes which
+// violate the per-OID constraints, and the unknown attribute and multi-valued
+// RDN switches of the policy.
+func evaluateSubjectAttributes(el *field.ErrorList, path *field.Path, policy *cmpolicy.PolicyX509Subject, allowedCommonName *string, rdns pkix.RDNSequence) {
+	values := subjectValues(rdns)
+	for i := range policy.AllowedAttributes {
+		attr := &policy.AllowedAttributes[i]
+		checks.StringSlice(el, path.Child("allowedAttributes").Index(i), &attr.AllowedValues, values[attr.OID])
+	}
+
+	if policy.AllowMultiValuedRDNs != nil && !*policy.AllowMultiValuedRDNs {
+		for _, rdn := range rdns {
+			if len(rdn) > 1 {
+				*el = append(*el, field.Invalid(path.Child("allowMultiValuedRDNs"), pkix.RDNSequence{rdn}.String(), "multi-valued RDNs are not allowed"))
+			}
+		}
+	}
+
+	if policy.AllowUnknownSubjectAttributes != nil && !*policy.AllowUnknownSubjectAttributes {
+		known := knownSubjectAttributes(policy, allowedCommonName)
+		for _, rdn := range rdns {
+			for _, atv := range rdn {
+				oid := atv.Type.String()
+				if !known[oid] {
+					*el = append(*el, field.Invalid(path.Child("allowUnknownSubjectAttributes"), oid, "subject attribute is not constrained by policy"))
+					// Only report each unknown attribute once.
+					known[oid] = true
+				}
+			}
+		}
+	}
+}
+
+// knownSubjectAttributes returns the set of attribute OIDs that are
+// constrained by the policy.
+func knownSubjectAttributes(policy *cmpolicy.PolicyX509Subject, allowedCommonName *string) map[string]bool {
+	known := make(map[string]bool)
+	for oid, constrained := range map[string]bool{
+		oidCommonName.String():         allowedCommonName != nil,
+		oidSerialNumber.String():       policy.AllowedSerialNumber != nil,
+		oidCountry.String():            policy.AllowedCountries != nil,
+		oidLocality.String():           policy.AllowedLocalities != nil,
+		oidProvince.String():           policy.AllowedProvinces != nil,
+		oidStreetAddress.String():      policy.AllowedStreetAddresses != nil,
+		oidOrganization.String():       policy.AllowedOrganizations != nil,
+		oidOrganizationalUnit.String(): policy.AllowedOrganizationalUnits != nil,
+		oidTitle.String():              policy.AllowedTitles != nil,
+		oidPostalCode.String():         policy.AllowedPostalCodes != nil,
+		oidUID.String():                policy.AllowedUIDs != nil,
+		oidDomainComponent.String():    policy.AllowedDomainComponents != nil,
+		oidEmailAddress.String():       policy.AllowedEmailAddresses != nil,
+	} {
+		if constrained {
+			known[oid] = true
+		}
+	}
+	for _, attr := range policy.AllowedAttributes {
+		known[attr.OID] = true
+	}
+	return known
+}
+
+// evaluateSingleValuedAttributes will add an error for each single valued
+// attribute which occurs more than once in the subject. Returns false if any
+// did, in which case the request should not be evaluated any further.
+func evaluateSingleValuedAttributes(el *field.ErrorList, path *field.Path, rdns pkix.RDNSequence) bool {
+	n := len(*el)
+	values := subjectValues(rdns)
+	for _, attr := range singleValuedAttributes {
+		if v := values[attr.oid.String()]; len(v) > 1 {
+			*el = append(*el, field.Invalid(path, v, fmt.Sprintf("subject must not contain more than one %s", attr.name)))
+		}
+	}
+	return len(*el) == n
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// mustRawSubject returns the DER encoding of the subject.
+func mustRawSubject(t *testing.T, rdns pkix.RDNSequence) []byte {
+	t.Helper()
+
+	raw, err := asn1.Marshal(rdns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func atv(oid asn1.ObjectIdentifier, value string) pkix.AttributeTypeAndValue {
+	return pkix.AttributeTypeAndValue{Type: oid, Value: value}
+}
+
+func TestDistinguishedName(t *testing.T) {
+	tests := map[string]struct {
+		rdns  pkix.RDNSequence
+		expDN string
+	}{
+		"single valued RDNs are written in reverse order": {
+			rdns: pkix.RDNSequence{
+				{atv(oidCountry, "GB")},
+				{atv(oidOrganization, "Acme")},
+				{atv(oidCommonName, "example.com")},
+			},
+			expDN: "CN=example.com,O=Acme,C=GB",
+		},
+		"multi-valued RDNs are joined with '+'": {
+			rdns: pkix.RDNSequence{
+				{atv(oidOrganization, "Acme")},
+				{atv(oidCommonName, "example.com"), atv(oidUID, "1000")},
+			},
+			expDN: "CN=example.com+UID=1000,O=Acme",
+		},
+		"special characters are escaped": {
+			rdns: pkix.RDNSequence{
+				{atv(oidOrganization, "Acme, Inc.")},
+				{atv(oidCommonName, " x+y ")},
+			},
+			expDN: `CN=\ x\+y\ ,O=Acme\, Inc.`,
+		},
+		"attribute types without a short name are written as their OID": {
+			rdns: pkix.RDNSequence{
+				{atv(asn1.ObjectIdentifier{2, 5, 4, 4}, "Smith")},
+			},
+			expDN: "2.5.4.4=Smith",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if dn := distinguishedName(test.rdns); dn != test.expDN {
+				t.Errorf("unexpected distinguished name: exp=%q got=%q", test.expDN, dn)
+			}
+		})
+	}
+}
+
+func TestEvaluateSingleValuedAttributes(t *testing.T) {
+	tests := map[string]struct {
+		rdns    pkix.RDNSequence
+		expErrs int
+	}{
+		"single common name and serial number": {
+			rdns: pkix.RDNSequence{
+				{atv(oidCommonName, "ok.example.com")},
+				{atv(oidSerialNumber, "1")},
+			},
+			expErrs: 0,
+		},
+		"duplicate common names": {
+			rdns: pkix.RDNSequence{
+				{atv(oidCommonName, "evil.com")},
+				{atv(oidCommonName, "ok.example.com")},
+			},
+			expErrs: 1,
+		},
+		"duplicate common names in a multi-valued RDN": {
+			rdns: pkix.RDNSequence{
+				{atv(oidCommonName, "evil.com"), atv(oidCommonName, "ok.example.com")},
+			},
+			expErrs: 1,
+		},
+		"duplicate serial numbers": {
+			rdns: pkix.RDNSequence{
+				{atv(oidSerialNumber, "1")},
+				{atv(oidSerialNumber, "2")},
+			},
+			expErrs: 1,
+		},
+		"duplicate multi valued attributes": {
+			rdns: pkix.RDNSequence{
+				{atv(oidOrganizationalUnit, "a")},
+				{atv(oidOrganizationalUnit, "b")},
+			},
+			expErrs: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			ok := evaluateSingleValuedAttributes(&el, field.NewPath("spec"), test.rdns)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+			if ok != (test.expErrs == 0) {
+				t.Errorf("unexpected result: got=%t", ok)
+			}
+		})
+	}
+}
+
+func TestEvaluateSubjectAttributes(t *testing.T) {
+	allowMultiValuedRDNs := false
+	allowUnknownSubjectAttributes := false
+	cn := "*"
+
+	tests := map[string]struct {
+		policy  *cmpolicy.PolicyX509Subject
+		rdns    pkix.RDNSequence
+		expErrs int
+	}{
+		"allowed attribute values": {
+			policy: &cmpolicy.PolicyX509Subject{
+				AllowedAttributes: []cmpolicy.PolicyX509SubjectAttribute{
+					{OID: "2.5.4.4", AllowedValues: []string{"Smith"}},
+				},
+			},
+			rdns:    pkix.RDNSequence{{atv(asn1.ObjectIdentifier{2, 5, 4, 4}, "Smith")}},
+			expErrs: 0,
+		},
+		"attribute value which is not allowed": {
+			policy: &cmpolicy.PolicyX509Subject{
+				AllowedAttributes: []cmpolicy.PolicyX509SubjectAttribute{
+					{OID: "2.5.4.4", AllowedValues: []string{"Smith"}},
+				},
+			},
+			rdns:    pkix.RDNSequence{{atv(asn1.ObjectIdentifier{2, 5, 4, 4}, "Jones")}},
+			expErrs: 1,
+		},
+		"multi-valued RDN which is not allowed": {
+			policy: &cmpolicy.PolicyX509Subject{AllowMultiValuedRDNs: &allowMultiValuedRDNs},
+			rdns: pkix.RDNSequence{
+				{atv(oidCommonName, "example.com"), atv(oidOrganization, "Acme")},
+			},
+			expErrs: 1,
+		},
+		"unknown attribute which is not allowed": {
+			policy: &cmpolicy.PolicyX509Subject{AllowUnknownSubjectAttributes: &allowUnknownSubjectAttributes},
+			rdns: pkix.RDNSequence{
+				{atv(oidCommonName, "example.com")},
+				{atv(oidTitle, "CEO")},
+				{atv(oidTitle, "CTO")},
+			},
+			expErrs: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var el field.ErrorList
+			evaluateSubjectAttributes(&el, field.NewPath("spec", "allowedSubject"), test.policy, &cn, test.rdns)
+			if len(el) != test.expErrs {
+				t.Errorf("unexpected errors: exp=%d got=%v", test.expErrs, el)
+			}
+		})
+	}
+}
+
+func TestEvaluateCertificateRequestDuplicateCommonNames(t *testing.T) {
+	allowedCommonName := "*.example.com"
+	policy := &cmpolicy.CertificateRequestPolicy{
+		Spec: cmpolicy.CertificateRequestPolicySpec{
+			PolicyConstraints: cmpolicy.PolicyConstraints{AllowedCommonName: &allowedCommonName},
+		},
+	}
+
+	// Evaluated as the last common name, ok.example.com, if only the decoded
+	// subject fields were inspected.
+	cr := &cmapi.CertificateRequest{
+		Spec: cmapi.CertificateRequestSpec{
+			Request: mustCSR(t, &x509.CertificateRequest{
+				RawSubject: mustRawSubject(t, pkix.RDNSequence{
+					{atv(oidCommonName, "evil.com")},
+					{atv(oidCommonName, "ok.example.com")},
+				}),
+			}),
+		},
+	}
+
+	var el field.ErrorList
+	if err := New(nil, Options{}).EvaluateCertificateRequest(context.TODO(), &el, policy, cr); err != nil {
+		t.Fatal(err)
+	}
+	if len(el) != 1 {
+		t.Errorf("expected request with duplicate common names to be denied, got=%v", el)
+	}
+}
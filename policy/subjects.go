@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+	"github.com/cert-manager/policy-approver/policy/checks/wildcard"
+)
+
+// isBound returns true if the CertificateRequestPolicy is bound to the
+// requester of the CertificateRequest in the given namespace scope, through
+// the RBAC "use" permission and the Subjects of the policy.
+func (p *Policy) isBound(ctx context.Context, crp *cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest, namespace string) (bool, error) {
+	subjects := crp.Spec.Subjects
+	if subjects != nil && subjects.Mode != nil && *subjects.Mode == cmpolicy.PolicySubjectsModeAlternative {
+		if matchesSubjects(subjects, cr) {
+			return true, nil
+		}
+		return p.canUse(ctx, crp, cr, namespace)
+	}
+
+	if subjects != nil && !matchesSubjects(subjects, cr) {
+		return false, nil
+	}
+
+	return p.canUse(ctx, crp, cr, namespace)
+}
+
+// canUse performs a SubjectAccessReview for whether the requester of the
+// CertificateRequest may "use" the CertificateRequestPolicy in the given
+// namespace scope.
+func (p *Policy) canUse(ctx context.Context, crp *cmpolicy.CertificateRequestPolicy, cr *cmapi.CertificateRequest, namespace string) (bool, error) {
+	extra := make(map[string]authzv1.ExtraValue)
+	for k, v := range cr.Spec.Extra {
+		extra[k] = v
+	}
+
+	rev := &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			User:   cr.Spec.Username,
+			Groups: cr.Spec.Groups,
+			Extra:  extra,
+			UID:    cr.Spec.UID,
+
+			ResourceAttributes: &authzv1.ResourceAttributes{
+				Group:     "policy.cert-manager.io",
+				Resource:  "certificaterequestpolicies",
+				Name:      crp.Name,
+				Namespace: namespace,
+				Verb:      "use",
+			},
+		},
+	}
+	if err := p.Create(ctx, rev); err != nil {
+		return false, err
+	}
+
+	return rev.Status.Allowed, nil
+}
+
+// matchesSubjects returns true if the requester of the CertificateRequest
+// matches the Subjects. Subjects without any matcher match no requester, so
+// that an empty Subjects never binds a policy to every requester.
+func matchesSubjects(subjects *cmpolicy.PolicySubjects, cr *cmapi.CertificateRequest) bool {
+	// Match none
+	if subjects.Users == nil && subjects.Groups == nil && subjects.ServiceAccounts == nil && len(subjects.Extra) == 0 {
+		return false
+	}
+
+	for _, extra := range subjects.Extra {
+		if !matchesExtra(extra, cr.Spec.Extra) {
+			return false
+		}
+	}
+
+	// Only Extra matchers
+	if subjects.Users == nil && subjects.Groups == nil && subjects.ServiceAccounts == nil {
+		return true
+	}
+
+	if subjects.Users != nil && wildcard.Contains(*subjects.Users, cr.Spec.Username) {
+		return true
+	}
+
+	if subjects.Groups != nil {
+		for _, group := range cr.Spec.Groups {
+			if wildcard.Contains(*subjects.Groups, group) {
+				return true
+			}
+		}
+	}
+
+	if subjects.ServiceAccounts != nil {
+		if namespace, name, ok := parseServiceAccountUsername(cr.Spec.Username); ok {
+			for _, sa := range *subjects.ServiceAccounts {
+				if wildcard.Matchs(sa.Namespace, namespace) && wildcard.Matchs(sa.Name, name) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// matchesExtra returns true if any value of the matcher key in the extra user
+// info matches any of the matcher values.
+func matchesExtra(matcher cmpolicy.PolicySubjectExtra, extra map[string][]string) bool {
+	for _, value := range extra[matcher.Key] {
+		if wildcard.Contains(matcher.Values, value) {
+			return true
+		}
+	}
+	return false
+}
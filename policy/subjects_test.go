@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpolicy "github.com/cert-manager/policy-approver/api/v1alpha1"
+)
+
+// sarClient answers every SubjectAccessReview with the given decision.
+type sarClient struct {
+	client.Client
+	allowed bool
+}
+
+func (c sarClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if rev, ok := obj.(*authzv1.SubjectAccessReview); ok {
+		rev.Status.Allowed = c.allowed
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestMatchesSubjects(t *testing.T) {
+	cr := &cmapi.CertificateRequest{
+		Spec: cmapi.CertificateRequestSpec{
+			Username: "system:serviceaccount:team-a:builder",
+			Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+			Extra:    map[string][]string{"team": {"a"}},
+		},
+	}
+
+	tests := map[string]struct {
+		subjects *cmpolicy.PolicySubjects
+		exp      bool
+	}{
+		"no matchers: match none": {
+			subjects: &cmpolicy.PolicySubjects{},
+			exp:      false,
+		},
+		"user matches": {
+			subjects: &cmpolicy.PolicySubjects{Users: &[]string{"system:serviceaccount:team-a:*"}},
+			exp:      true,
+		},
+		"empty users: match none": {
+			subjects: &cmpolicy.PolicySubjects{Users: &[]string{}},
+			exp:      false,
+		},
+		"group matches": {
+			subjects: &cmpolicy.PolicySubjects{Groups: &[]string{"system:serviceaccounts"}},
+			exp:      true,
+		},
+		"service account matches": {
+			subjects: &cmpolicy.PolicySubjects{ServiceAccounts: &[]cmpolicy.PolicySubjectServiceAccount{{Namespace: "team-a", Name: "*"}}},
+			exp:      true,
+		},
+		"service account in another namespace": {
+			subjects: &cmpolicy.PolicySubjects{ServiceAccounts: &[]cmpolicy.PolicySubjectServiceAccount{{Namespace: "team-b", Name: "*"}}},
+			exp:      false,
+		},
+		"only extra matchers which match": {
+			subjects: &cmpolicy.PolicySubjects{Extra: []cmpolicy.PolicySubjectExtra{{Key: "team", Values: []string{"a"}}}},
+			exp:      true,
+		},
+		"user matches but extra does not": {
+			subjects: &cmpolicy.PolicySubjects{
+				Users: &[]string{"*"},
+				Extra: []cmpolicy.PolicySubjectExtra{{Key: "team", Values: []string{"b"}}},
+			},
+			exp: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if match := matchesSubjects(test.subjects, cr); match != test.exp {
+				t.Errorf("unexpected match: exp=%t got=%t", test.exp, match)
+			}
+		})
+	}
+}
+
+func TestIsBound(t *testing.T) {
+	additional, alternative := cmpolicy.PolicySubjectsModeAdditional, cmpolicy.PolicySubjectsModeAlternative
+	cr := &cmapi.CertificateRequest{
+		Spec: cmapi.CertificateRequestSpec{Username: "alice", Groups: []string{"system:authenticated"}},
+	}
+
+	tests := map[string]struct {
+		subjects *cmpolicy.PolicySubjects
+		canUse   bool
+		exp      bool
+	}{
+		"no subjects, can use": {
+			subjects: nil, canUse: true, exp: true,
+		},
+		"no subjects, cannot use": {
+			subjects: nil, canUse: false, exp: false,
+		},
+		"additional, matching subjects, cannot use": {
+			subjects: &cmpolicy.PolicySubjects{Mode: &additional, Users: &[]string{"alice"}},
+			canUse:   false,
+			exp:      false,
+		},
+		"additional, not matching subjects, can use": {
+			subjects: &cmpolicy.PolicySubjects{Mode: &additional, Users: &[]string{"bob"}},
+			canUse:   true,
+			exp:      false,
+		},
+		"alternative, matching subjects, cannot use": {
+			subjects: &cmpolicy.PolicySubjects{Mode: &alternative, Users: &[]string{"alice"}},
+			canUse:   false,
+			exp:      true,
+		},
+		"alternative, not matching subjects, can use": {
+			subjects: &cmpolicy.PolicySubjects{Mode: &alternative, Users: &[]string{"bob"}},
+			canUse:   true,
+			exp:      true,
+		},
+		"alternative, no matchers, cannot use": {
+			subjects: &cmpolicy.PolicySubjects{Mode: &alternative},
+			canUse:   false,
+			exp:      false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := sarClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build(), allowed: test.canUse}
+			crp := &cmpolicy.CertificateRequestPolicy{
+				Spec: cmpolicy.CertificateRequestPolicySpec{Subjects: test.subjects},
+			}
+
+			bound, err := New(c, Options{}).isBound(context.TODO(), crp, cr, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bound != test.exp {
+				t.Errorf("unexpected bound: exp=%t got=%t", test.exp, bound)
+			}
+		})
+	}
+}
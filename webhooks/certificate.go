@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/cert-manager/policy-approver/policy/requester"
+)
+
+// CertificatePath is the path the Certificate requester webhook is served on.
+const CertificatePath = "/mutate-cert-manager-io-v1-certificate"
+
+//+kubebuilder:webhook:path=/mutate-cert-manager-io-v1-certificate,mutating=true,failurePolicy=fail,sideEffects=None,groups=cert-manager.io,resources=certificates,verbs=create;update,versions=v1,name=certificates.policy.cert-manager.io,admissionReviewVersions={v1,v1beta1}
+
+// CertificateRequester stamps the signed identity of the requester onto
+// Certificates whenever their spec is created or modified.
+type CertificateRequester struct {
+	log     logr.Logger
+	signer  *requester.Signer
+	decoder *admission.Decoder
+}
+
+func NewCertificateRequester(log logr.Logger, signer *requester.Signer) *CertificateRequester {
+	return &CertificateRequester{
+		log:    log.WithName("certificate-requester"),
+		signer: signer,
+	}
+}
+
+// Handle stamps the requester annotation onto the Certificate if it is being
+// created, its spec is being modified, or its requester annotation is being
+// modified. The Certificate is decoded as an unstructured object, so that the
+// patch only touches the annotations, and never fields unknown to this
+// version of the cert-manager API.
+// The signed annotation is bound to the name of the Certificate, which is not
+// yet generated when a Certificate using generateName is admitted, so such
+// Certificates are denied.
+func (c *CertificateRequester) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cert := new(unstructured.Unstructured)
+	if err := c.decoder.Decode(req, cert); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldCert := new(unstructured.Unstructured)
+		if err := c.decoder.DecodeRaw(req.OldObject, oldCert); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		if apiequality.Semantic.DeepEqual(oldCert.Object["spec"], cert.Object["spec"]) &&
+			oldCert.GetAnnotations()[requester.AnnotationKey] == cert.GetAnnotations()[requester.AnnotationKey] {
+			return admission.Allowed("Certificate spec unchanged")
+		}
+	}
+
+	if len(cert.GetName()) == 0 {
+		return admission.Denied("Certificate must set metadata.name rather than metadata.generateName, " +
+			"since its requester annotation is bound to its name")
+	}
+
+	extra := make(map[string][]string)
+	for k, v := range req.UserInfo.Extra {
+		extra[k] = v
+	}
+
+	value, err := c.signer.Sign(req.Namespace, cert.GetName(), requester.Identity{
+		Username: req.UserInfo.Username,
+		UID:      req.UserInfo.UID,
+		Groups:   req.UserInfo.Groups,
+		Extra:    extra,
+	})
+	if err != nil {
+		c.log.Error(err, "failed to sign requester", "namespace", req.Namespace, "name", cert.GetName())
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	annotations := cert.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[requester.AnnotationKey] = value
+	cert.SetAnnotations(annotations)
+
+	marshaled, err := json.Marshal(cert.Object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// InjectDecoder injects the admission decoder.
+func (c *CertificateRequester) InjectDecoder(d *admission.Decoder) error {
+	c.decoder = d
+	return nil
+}
+
+// SetupWithManager registers the webhook with the Manager's webhook server.
+func (c *CertificateRequester) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(CertificatePath, &webhook.Admission{Handler: c})
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authnv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/cert-manager/policy-approver/policy/requester"
+)
+
+func TestCertificateRequesterHandle(t *testing.T) {
+	signer, err := requester.NewSigner(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(runtime.NewScheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCertificateRequester(ctrl.Log, signer)
+	if err := c.InjectDecoder(decoder); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fields which are unknown to the vendored cert-manager API must not be
+	// removed by the patch.
+	cert := []byte(`{
+		"apiVersion": "cert-manager.io/v1",
+		"kind": "Certificate",
+		"metadata": {"name": "test", "namespace": "test"},
+		"spec": {
+			"secretName": "test",
+			"secretTemplate": {"labels": {"a": "b"}},
+			"additionalOutputFormats": [{"type": "CombinedPEM"}],
+			"privateKey": {"rotationPolicy": "Always"},
+			"issuerRef": {"name": "ca"}
+		}
+	}`)
+
+	resp := c.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: "test",
+			Object:    runtime.RawExtension{Raw: cert},
+			UserInfo:  authnv1.UserInfo{Username: "alice"},
+		},
+	})
+	if !resp.Allowed {
+		t.Fatalf("expected Certificate to be allowed: %v", resp.Result)
+	}
+	if len(resp.Patches) == 0 {
+		t.Fatal("expected requester annotation to be patched")
+	}
+	for _, patch := range resp.Patches {
+		if !strings.HasPrefix(patch.Path, "/metadata/annotations") {
+			t.Errorf("unexpected patch of %q: %s", patch.Path, patch.Operation)
+		}
+	}
+}
+
+func TestCertificateRequesterHandleGenerateName(t *testing.T) {
+	signer, err := requester.NewSigner(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(runtime.NewScheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCertificateRequester(ctrl.Log, signer)
+	if err := c.InjectDecoder(decoder); err != nil {
+		t.Fatal(err)
+	}
+
+	// The name is generated after mutating admission, so there is no name to
+	// bind the requester annotation to.
+	cert := []byte(`{
+		"apiVersion": "cert-manager.io/v1",
+		"kind": "Certificate",
+		"metadata": {"generateName": "test-", "namespace": "test"},
+		"spec": {"secretName": "test", "issuerRef": {"name": "ca"}}
+	}`)
+
+	resp := c.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: "test",
+			Object:    runtime.RawExtension{Raw: cert},
+			UserInfo:  authnv1.UserInfo{Username: "alice"},
+		},
+	})
+	if resp.Allowed {
+		t.Fatal("expected Certificate using generateName to be denied")
+	}
+	if len(resp.Patches) > 0 {
+		t.Errorf("unexpected patches: %v", resp.Patches)
+	}
+	if resp.Result == nil || !strings.Contains(string(resp.Result.Reason), "generateName") {
+		t.Errorf("unexpected result: %v", resp.Result)
+	}
+}